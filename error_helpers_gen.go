@@ -4,6 +4,7 @@
 // error_helpers_gen.go generates GetJSONxxx helper methods for response structs.
 // It scans openapi.gen.go for structs ending with "Response" that have fields prefixed with "JSON"
 // and emits a file error_helpers.gen.go containing methods like GetJSON418().
+// For every response struct carrying the raw Body it also emits a GetBody() method.
 //
 // Usage:
 //   //go:generate go run error_helpers_gen.go
@@ -22,6 +23,7 @@ import (
 	"go/token"
 	"log"
 	"os"
+	"sort"
 	"strings"
 )
 
@@ -72,6 +74,13 @@ func main() {
 				continue
 			}
 
+			// Emit raw body accessor for response structs
+			if strings.HasSuffix(ts.Name.Name, "Response") && hasField(st, "Body") {
+				builder.WriteString(fmt.Sprintf("func (r *%s) GetBody() []byte {\n", ts.Name.Name))
+				builder.WriteString("\treturn r.Body\n")
+				builder.WriteString("}\n\n")
+			}
+
 			// For each field in the struct
 			for _, field := range st.Fields.List {
 				// Look for named fields like JSON418
@@ -119,7 +128,14 @@ func main() {
 	if len(interfaces) != 0 {
 		builder.WriteString("type APIErrorResponses interface {\n")
 		builder.WriteString("\tStatusCode() int\n")
-		for k, v := range interfaces {
+		builder.WriteString("\tGetBody() []byte\n")
+		keys := make([]string, 0, len(interfaces))
+		for k := range interfaces {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := interfaces[k]
 			if v.Count > 5 {
 				builder.WriteString(fmt.Sprintf("\tGet%s() %s\n", k, v.Type))
 			}
@@ -140,3 +156,14 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func hasField(st *ast.StructType, name string) bool {
+	for _, field := range st.Fields.List {
+		for _, n := range field.Names {
+			if n.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -2,6 +2,10 @@
 
 package threecommas
 
+func (r *GetCurrencyRatesResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetCurrencyRatesResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -66,6 +70,10 @@ type GetJSON504 interface {
 	JSON504() *GatewayTimeout
 }
 
+func (r *GetCurrencyRatesWithLeverageDataResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetCurrencyRatesWithLeverageDataResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -98,6 +106,10 @@ func (r *GetCurrencyRatesWithLeverageDataResponse) GetJSON504() *GatewayTimeout
 	return r.JSON504
 }
 
+func (r *GetMarketListResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetMarketListResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -130,6 +142,10 @@ func (r *GetMarketListResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *GetMarketPairsResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetMarketPairsResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -162,6 +178,10 @@ func (r *GetMarketPairsResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *ListBotsResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *ListBotsResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -194,6 +214,10 @@ func (r *ListBotsResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *CreateDcaBotResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *CreateDcaBotResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -226,6 +250,10 @@ func (r *CreateDcaBotResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *GetPairsBlacklistResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetPairsBlacklistResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -258,6 +286,10 @@ func (r *GetPairsBlacklistResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *GetBotsStatsResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetBotsStatsResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -290,6 +322,10 @@ func (r *GetBotsStatsResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *GetBotsStatsByDateResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetBotsStatsByDateResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -322,6 +358,10 @@ func (r *GetBotsStatsByDateResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *ListStrategiesResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *ListStrategiesResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -354,6 +394,10 @@ func (r *ListStrategiesResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *UpdatePairsBlacklistResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *UpdatePairsBlacklistResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -386,6 +430,10 @@ func (r *UpdatePairsBlacklistResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *CancelAllDealsResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *CancelAllDealsResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -418,6 +466,10 @@ func (r *CancelAllDealsResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *CopyAndCreateBotResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *CopyAndCreateBotResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -450,6 +502,10 @@ func (r *CopyAndCreateBotResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *GetDealsStatsResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetDealsStatsResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -482,6 +538,10 @@ func (r *GetDealsStatsResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *DeleteBotResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *DeleteBotResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -514,6 +574,10 @@ func (r *DeleteBotResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *DisableBotResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *DisableBotResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -546,6 +610,10 @@ func (r *DisableBotResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *EnableBotResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *EnableBotResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -578,6 +646,10 @@ func (r *EnableBotResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *PanicSellAllDealsResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *PanicSellAllDealsResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -610,6 +682,10 @@ func (r *PanicSellAllDealsResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *GetProfitByDayResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetProfitByDayResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -642,6 +718,10 @@ func (r *GetProfitByDayResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *GetBotResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetBotResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -674,6 +754,10 @@ func (r *GetBotResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *UpdateBotResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *UpdateBotResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -706,6 +790,10 @@ func (r *UpdateBotResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *ListDealsResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *ListDealsResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -738,6 +826,10 @@ func (r *ListDealsResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *CancelDealResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *CancelDealResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -770,6 +862,10 @@ func (r *CancelDealResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *GetDealDataForAddingFundsResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetDealDataForAddingFundsResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -802,6 +898,10 @@ func (r *GetDealDataForAddingFundsResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *GetTradesOfDealResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetTradesOfDealResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -834,6 +934,10 @@ func (r *GetTradesOfDealResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *PanicSellDealResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *PanicSellDealResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -866,6 +970,10 @@ func (r *PanicSellDealResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *PanicSellDealStepResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *PanicSellDealStepResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -898,6 +1006,10 @@ func (r *PanicSellDealStepResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *GetDealResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *GetDealResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -930,6 +1042,10 @@ func (r *GetDealResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *UpdateDealResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *UpdateDealResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -962,6 +1078,10 @@ func (r *UpdateDealResponse) GetJSON504() *GatewayTimeout {
 	return r.JSON504
 }
 
+func (r *ValidateAuthenticationResponse) GetBody() []byte {
+	return r.Body
+}
+
 func (r *ValidateAuthenticationResponse) GetJSON400() *BadRequest {
 	return r.JSON400
 }
@@ -996,13 +1116,14 @@ func (r *ValidateAuthenticationResponse) GetJSON504() *GatewayTimeout {
 
 type APIErrorResponses interface {
 	StatusCode() int
+	GetBody() []byte
+	GetJSON400() *BadRequest
+	GetJSON401() *Unauthorized
+	GetJSON403() *Forbidden
 	GetJSON404() *NotFound
 	GetJSON418() *IPAutoBanned
 	GetJSON429() *RateLimitExceeded
 	GetJSON500() *InternalServerError
 	GetJSON504() *GatewayTimeout
-	GetJSON400() *BadRequest
-	GetJSON401() *Unauthorized
-	GetJSON403() *Forbidden
 }
 
@@ -0,0 +1,47 @@
+package threecommas
+
+import (
+	"context"
+	"sync"
+)
+
+// batchConcurrency bounds how many operations RunBatch runs at once. The
+// rate limiter paces the requests, this only bounds the goroutines waiting
+// on it.
+const batchConcurrency = 4
+
+// RunBatch runs ops with bounded concurrency and returns their results and
+// errors in the order of ops. The operations are expected to call the client,
+// so they are paced by its rate limiter. Operations that have not started
+// when ctx is cancelled are skipped and get the context's error.
+func RunBatch(ctx context.Context, ops []func(ctx context.Context) (any, error)) ([]any, []error) {
+	results := make([]any, len(ops))
+	errs := make([]error, len(ops))
+
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i, op := range ops {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(ops); j++ {
+				errs[j] = err
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i], errs[i] = op(ctx)
+		}()
+	}
+	wg.Wait()
+
+	return results, errs
+}
@@ -0,0 +1,94 @@
+package threecommas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBatch(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	boom := errors.New("boom")
+
+	ops := make([]func(context.Context) (any, error), 20)
+	for i := range ops {
+		ops[i] = func(context.Context) (any, error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				cur := maxInFlight.Load()
+				if n <= cur || maxInFlight.CompareAndSwap(cur, n) {
+					break
+				}
+			}
+			// Later ops finish first, the results must keep their order
+			time.Sleep(time.Duration(len(ops)-i) * time.Millisecond)
+			if i%5 == 0 {
+				return nil, fmt.Errorf("op %d: %w", i, boom)
+			}
+			return i, nil
+		}
+	}
+
+	results, errs := RunBatch(context.Background(), ops)
+	require.Len(t, results, len(ops))
+	require.Len(t, errs, len(ops))
+	for i := range ops {
+		if i%5 == 0 {
+			require.ErrorIs(t, errs[i], boom)
+			require.Nil(t, results[i])
+			continue
+		}
+		require.NoError(t, errs[i])
+		require.Equal(t, i, results[i])
+	}
+	require.LessOrEqual(t, maxInFlight.Load(), int32(batchConcurrency))
+	require.Greater(t, maxInFlight.Load(), int32(1), "ops run concurrently")
+
+	results, errs = RunBatch(context.Background(), nil)
+	require.Empty(t, results)
+	require.Empty(t, errs)
+}
+
+func TestRunBatchContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var started atomic.Int32
+	release := make(chan struct{})
+	ops := make([]func(context.Context) (any, error), 10)
+	for i := range ops {
+		ops[i] = func(ctx context.Context) (any, error) {
+			started.Add(1)
+			<-release
+			return i, nil
+		}
+	}
+
+	done := make(chan struct{})
+	var errs []error
+	go func() {
+		_, errs = RunBatch(ctx, ops)
+		close(done)
+	}()
+
+	// The first batch blocks, cancel before the rest can start
+	require.Eventually(t, func() bool { return started.Load() == batchConcurrency }, time.Second, time.Millisecond)
+	cancel()
+	close(release)
+	<-done
+
+	require.Equal(t, int32(batchConcurrency), started.Load())
+	for i, err := range errs {
+		if i < batchConcurrency {
+			require.NoError(t, err)
+			continue
+		}
+		require.ErrorIs(t, err, context.Canceled)
+	}
+}
@@ -0,0 +1,108 @@
+package threecommas
+
+import "strconv"
+
+// The generated Bot already has IsEnabled and ActiveDealsCount fields, so the
+// accessors below use names that don't collide with them.
+
+// IsActive reports whether the bot is enabled. It is safe to call on a nil Bot.
+func (b *Bot) IsActive() bool {
+	if b == nil {
+		return false
+	}
+	return b.IsEnabled
+}
+
+// PairCount returns the number of pairs the bot trades. It is safe to call on a nil Bot.
+func (b *Bot) PairCount() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.Pairs)
+}
+
+// ActiveDealCount returns the number of active deals of the bot, falling back
+// to the embedded active deals when the count is not set. It is safe to call
+// on a nil Bot.
+func (b *Bot) ActiveDealCount() int {
+	if b == nil {
+		return 0
+	}
+	if b.ActiveDealsCount == 0 {
+		return len(b.ActiveDeals)
+	}
+	return b.ActiveDealsCount
+}
+
+// PlannedOrder is a single step of a bot's safety order ladder.
+type PlannedOrder struct {
+	// Position is the 1-based index of the safety order.
+	Position int
+	// Volume is the volume of this safety order.
+	Volume float64
+	// StepPercentage is the price deviation from the previous order.
+	StepPercentage float64
+	// DeviationPercentage is the cumulative price deviation from the base order.
+	DeviationPercentage float64
+	// TotalVolume is the cumulative volume of all safety orders up to and
+	// including this one, the base order is not included.
+	TotalVolume float64
+}
+
+// SafetyOrderSchedule computes the planned safety order ladder the same way
+// the 3commas bot preview does: every order scales the previous volume by the
+// martingale volume coefficient and the previous step by the martingale step
+// coefficient. Missing coefficients default to 1. It returns nil when the bot
+// has no safety orders configured or the volume or step can't be parsed.
+func (b *Bot) SafetyOrderSchedule() []PlannedOrder {
+	if b == nil || b.MaxSafetyOrders == nil || *b.MaxSafetyOrders <= 0 {
+		return nil
+	}
+
+	volume, ok := parseBotFloat(b.SafetyOrderVolume, 0)
+	if !ok || volume <= 0 {
+		return nil
+	}
+	step, ok := parseBotFloat(b.SafetyOrderStepPercentage, 0)
+	if !ok || step <= 0 {
+		return nil
+	}
+	volumeScale, ok := parseBotFloat(b.MartingaleVolumeCoefficient, 1)
+	if !ok {
+		return nil
+	}
+	stepScale, ok := parseBotFloat(b.MartingaleStepCoefficient, 1)
+	if !ok {
+		return nil
+	}
+
+	orders := make([]PlannedOrder, 0, *b.MaxSafetyOrders)
+	var deviation, total float64
+	for i := 1; i <= *b.MaxSafetyOrders; i++ {
+		deviation += step
+		total += volume
+		orders = append(orders, PlannedOrder{
+			Position:            i,
+			Volume:              volume,
+			StepPercentage:      step,
+			DeviationPercentage: deviation,
+			TotalVolume:         total,
+		})
+		volume *= volumeScale
+		step *= stepScale
+	}
+	return orders
+}
+
+// parseBotFloat parses one of the bot's decimal string fields, returning def
+// when the field is not set.
+func parseBotFloat(v *string, def float64) (float64, bool) {
+	if v == nil || *v == "" {
+		return def, true
+	}
+	f, err := strconv.ParseFloat(*v, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
@@ -0,0 +1,126 @@
+package threecommas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotAccessors(t *testing.T) {
+	tests := []struct {
+		name            string
+		bot             *Bot
+		wantActive      bool
+		wantPairs       int
+		wantActiveDeals int
+	}{
+		{
+			name: "nil bot",
+			bot:  nil,
+		},
+		{
+			name: "empty bot",
+			bot:  &Bot{},
+		},
+		{
+			name: "enabled bot",
+			bot: &Bot{
+				IsEnabled:        true,
+				Pairs:            Pairs{"USDT_DOGE", "USDT_BTC"},
+				ActiveDealsCount: 3,
+			},
+			wantActive:      true,
+			wantPairs:       2,
+			wantActiveDeals: 3,
+		},
+		{
+			name: "count falls back to embedded deals",
+			bot: &Bot{
+				Pairs:       Pairs{"USDT_DOGE"},
+				ActiveDeals: []Deal{{Id: 1}, {Id: 2}},
+			},
+			wantPairs:       1,
+			wantActiveDeals: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantActive, tt.bot.IsActive())
+			require.Equal(t, tt.wantPairs, tt.bot.PairCount())
+			require.Equal(t, tt.wantActiveDeals, tt.bot.ActiveDealCount())
+		})
+	}
+}
+
+func TestBotSafetyOrderSchedule(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(i int) *int { return &i }
+
+	tests := []struct {
+		name string
+		bot  *Bot
+		want []PlannedOrder
+	}{
+		{
+			name: "nil bot",
+		},
+		{
+			name: "no safety orders",
+			bot: &Bot{
+				SafetyOrderVolume:         str("10"),
+				SafetyOrderStepPercentage: str("1"),
+				MaxSafetyOrders:           num(0),
+			},
+		},
+		{
+			name: "missing volume",
+			bot: &Bot{
+				SafetyOrderStepPercentage: str("1"),
+				MaxSafetyOrders:           num(3),
+			},
+		},
+		{
+			name: "unparseable coefficient",
+			bot: &Bot{
+				SafetyOrderVolume:           str("10"),
+				SafetyOrderStepPercentage:   str("1"),
+				MartingaleVolumeCoefficient: str("two"),
+				MaxSafetyOrders:             num(3),
+			},
+		},
+		{
+			name: "coefficients default to 1",
+			bot: &Bot{
+				SafetyOrderVolume:         str("10"),
+				SafetyOrderStepPercentage: str("1.5"),
+				MaxSafetyOrders:           num(2),
+			},
+			want: []PlannedOrder{
+				{Position: 1, Volume: 10, StepPercentage: 1.5, DeviationPercentage: 1.5, TotalVolume: 10},
+				{Position: 2, Volume: 10, StepPercentage: 1.5, DeviationPercentage: 3, TotalVolume: 20},
+			},
+		},
+		{
+			name: "martingale",
+			bot: &Bot{
+				SafetyOrderVolume:           str("10"),
+				SafetyOrderStepPercentage:   str("1"),
+				MartingaleVolumeCoefficient: str("2"),
+				MartingaleStepCoefficient:   str("1.5"),
+				MaxSafetyOrders:             num(3),
+			},
+			want: []PlannedOrder{
+				{Position: 1, Volume: 10, StepPercentage: 1, DeviationPercentage: 1, TotalVolume: 10},
+				{Position: 2, Volume: 20, StepPercentage: 1.5, DeviationPercentage: 2.5, TotalVolume: 30},
+				{Position: 3, Volume: 40, StepPercentage: 2.25, DeviationPercentage: 4.75, TotalVolume: 70},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.bot.SafetyOrderSchedule())
+		})
+	}
+}
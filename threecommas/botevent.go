@@ -3,9 +3,14 @@ package threecommas
 import (
 	"fmt"
 	"hash/crc32"
+	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"weak"
 
 	"github.com/recomma/3commas-sdk-go/threecommas/eventparser"
 )
@@ -53,6 +58,13 @@ type BotEvent struct {
 	ProfitUSD        float64
 	ProfitPercentage float64
 
+	// SequenceIndex is the position of the event in the time sorted Events of
+	// its deal, starting at 0; events with the same CreatedAt keep the API
+	// order. It tells apart events that share a fingerprint, e.g. repeated
+	// take profits, and is not part of the fingerprint. It only shifts when a
+	// later fetch of the deal has events older than known ones.
+	SequenceIndex int
+
 	// Example: Averaging order (8 out of 9) executed. Price: market Size: 25.0654404 USDT (110.0 DOGE)
 	Text string
 }
@@ -69,20 +81,144 @@ func (event *BotEvent) Fingerprint() string {
 	)
 }
 
+// StableFingerprint is like Fingerprint, but drops the OrderPosition when the
+// OrderSize is unknown (0). A parse that only picked up the position, e.g. from
+// inference, then gets the same fingerprint as one without progress at all.
+// Fingerprint keeps the position and stays the identity used by
+// FingerprintAsID, DedupeConsecutive and DiffEvents.
+func (event *BotEvent) StableFingerprint() string {
+	position := event.OrderPosition
+	if event.OrderSize == 0 {
+		position = 0
+	}
+	return fmt.Sprintf(
+		"%s|%d|%d|%s|%s",
+		event.OrderType,
+		position,
+		event.OrderSize,
+		strings.ToUpper(event.Coin),
+		strings.ToUpper(event.QuoteCurrency),
+	)
+}
+
 // FingerprintAsID is an uint32 that can be used to identify the same BotEvent across different states
 // Could be seen as a replacement for a MarketOrder ID, however they share no relation
 func (event *BotEvent) FingerprintAsID() uint32 {
 	return crc32.ChecksumIEEE([]byte(event.Fingerprint()))
 }
 
+// AsMarketOrder converts the event into a MarketOrder so it can be fed into
+// code that expects one. OrderId is set to FingerprintAsID; fields without a
+// counterpart on BotEvent are left zero. MarketOrder has no coin field, so the
+// Coin is dropped. A "Cancelling" status has no spec counterpart and is
+// reported as Active: the order stays open until the cancel completes.
+func (event *BotEvent) AsMarketOrder() MarketOrder {
+	status := event.Status
+	if status == MarketOrderStatusString(eventparser.StatusCancelling) {
+		status = Active
+	}
+	return MarketOrder{
+		OrderId:       strconv.FormatUint(uint64(event.FingerprintAsID()), 10),
+		OrderType:     event.Type,
+		DealOrderType: event.OrderType,
+		StatusString:  status,
+		Rate:          formatEventFloat(event.Price),
+		Quantity:      formatEventFloat(event.Size),
+		Total:         formatEventFloat(event.QuoteVolume),
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+func formatEventFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// eventsCache memoizes the parsed events per *Deal. It is keyed by weak
+// pointers so entries are dropped once their Deal is garbage collected.
+// Deal is a generated type, so the cache can't live on the struct.
+var eventsCache sync.Map // weak.Pointer[Deal] -> *cachedEvents
+
+type cachedEvents struct {
+	count  int
+	first  any // address of the first raw event, detects a replaced BotEvents
+	ctx    eventparser.Context
+	events []BotEvent
+}
+
+// matches reports whether the cached events still hold for d: the same raw
+// events parsed with the same context, i.e. status and currencies.
+func (c *cachedEvents) matches(d *Deal) bool {
+	return c.count == len(d.BotEvents) && c.first == firstRawEvent(d) && c.ctx == d.eventContext()
+}
+
+func firstRawEvent(d *Deal) any {
+	if len(d.BotEvents) == 0 {
+		return nil
+	}
+	return &d.BotEvents[0]
+}
+
 // Events returns the parsed BotEvents sorted on CreatedAt
+//
+// The result is cached per *Deal pointer, so calling Events repeatedly on the
+// same Deal only parses the messages once. A copy of the Deal value has its own
+// address and parses its events again. The cache is invalidated when BotEvents
+// is replaced or changes length, or when Status, FromCurrency or ToCurrency
+// change; editing a message in place is not detected. The returned slice is a
+// copy and can be modified freely.
 func (d *Deal) Events() []BotEvent {
-	ctx := eventparser.Context{
+	if d == nil {
+		return nil
+	}
+
+	key := weak.Make(d)
+	if v, ok := eventsCache.Load(key); ok {
+		if cached := v.(*cachedEvents); cached.matches(d) {
+			return slices.Clone(cached.events)
+		}
+	}
+
+	ctx := d.eventContext()
+	events := d.parseEvents(ctx)
+	cached := &cachedEvents{
+		count:  len(d.BotEvents),
+		first:  firstRawEvent(d),
+		ctx:    ctx,
+		events: events,
+	}
+	if _, loaded := eventsCache.Swap(key, cached); !loaded {
+		runtime.AddCleanup(d, func(key weak.Pointer[Deal]) {
+			eventsCache.Delete(key)
+		}, key)
+	}
+
+	return slices.Clone(events)
+}
+
+// EventsByType returns the Events of orders of the given deal order type,
+// e.g. only the take profits.
+func (d *Deal) EventsByType(orderType MarketOrderDealOrderType) []BotEvent {
+	return Filter(d.Events(), BotEventFilterOrderType(orderType))
+}
+
+// EventsByAction returns the Events with the given action, e.g. only the
+// executed orders.
+func (d *Deal) EventsByAction(action BotEventAction) []BotEvent {
+	return Filter(d.Events(), BotEventFilterAction(action))
+}
+
+// eventContext returns the context the deal's messages are parsed with.
+func (d *Deal) eventContext() eventparser.Context {
+	pair := d.TradingPair()
+	return eventparser.Context{
 		Strategy:      DealStrategy(d),
-		BaseCurrency:  strings.ToUpper(d.ToCurrency),
-		QuoteCurrency: strings.ToUpper(d.FromCurrency),
+		BaseCurrency:  pair.Base,
+		QuoteCurrency: pair.Quote,
 	}
+}
 
+// parseEvents parses all bot events of the deal with ctx, bypassing the cache.
+func (d *Deal) parseEvents(ctx eventparser.Context) []BotEvent {
 	events := make([]BotEvent, 0, len(d.BotEvents))
 
 	for _, raw := range d.BotEvents {
@@ -117,13 +253,58 @@ func (d *Deal) Events() []BotEvent {
 		})
 	}
 
-	sort.Slice(events, func(i, j int) bool {
+	sort.SliceStable(events, func(i, j int) bool {
 		return events[i].CreatedAt.Before(events[j].CreatedAt)
 	})
+	inferSafetyPositions(events)
+	for i := range events {
+		events[i].SequenceIndex = i
+	}
 
 	return events
 }
 
+// inferSafetyPositions assigns an OrderPosition to safety orders whose message
+// carried no "(N out of M)" progress, so their fingerprints don't collide.
+// Positions follow a running counter over the sorted events: every placement
+// takes the next position, and an execution or cancellation resolves the
+// oldest placement that is still open. OrderSize stays 0 as it is unknown.
+func inferSafetyPositions(events []BotEvent) {
+	var last int
+	var open []int
+
+	for i := range events {
+		ev := &events[i]
+		if ev.OrderType != MarketOrderDealOrderTypeSafety {
+			continue
+		}
+
+		if ev.OrderPosition > 0 {
+			last = max(last, ev.OrderPosition)
+			if ev.Action == BotEventActionPlace {
+				open = append(open, ev.OrderPosition)
+			} else if idx := slices.Index(open, ev.OrderPosition); idx != -1 {
+				open = slices.Delete(open, idx, idx+1)
+			}
+			continue
+		}
+
+		switch {
+		case ev.Action == BotEventActionPlace:
+			last++
+			ev.OrderPosition = last
+			open = append(open, last)
+		case len(open) > 0:
+			ev.OrderPosition = open[0]
+			open = open[1:]
+		default:
+			// Resolved without a placement we know of
+			last++
+			ev.OrderPosition = last
+		}
+	}
+}
+
 func mapOrderType(t eventparser.OrderType) MarketOrderDealOrderType {
 	switch t {
 	case eventparser.OrderTypeBase:
@@ -157,3 +338,60 @@ func DealStrategy(d *Deal) eventparser.Strategy {
 		return eventparser.StrategyUnknown
 	}
 }
+
+// DedupeConsecutive drops every event whose FingerprintAsID, Action and Status
+// match the immediately preceding event, collapsing replayed states into the
+// first occurrence (and its timestamp). The input is not modified.
+func DedupeConsecutive(events []BotEvent) []BotEvent {
+	if events == nil {
+		return nil
+	}
+	out := make([]BotEvent, 0, len(events))
+	for i := range events {
+		if n := len(out); n > 0 && sameState(&out[n-1], &events[i]) {
+			continue
+		}
+		out = append(out, events[i])
+	}
+	return out
+}
+
+func sameState(a, b *BotEvent) bool {
+	return a.FingerprintAsID() == b.FingerprintAsID() &&
+		a.Action == b.Action &&
+		a.Status == b.Status
+}
+
+type eventKey struct {
+	createdAt int64
+	id        uint32
+	action    BotEventAction
+}
+
+func keyOf(event *BotEvent) eventKey {
+	return eventKey{
+		createdAt: event.CreatedAt.UnixNano(),
+		id:        event.FingerprintAsID(),
+		action:    event.Action,
+	}
+}
+
+// DiffEvents returns the events of next that are not in prev, e.g. the events
+// added to a deal between two polls. Events are matched on CreatedAt,
+// FingerprintAsID and Action; an event that occurs n times in prev only
+// matches its first n occurrences in next. The order of next is kept.
+func DiffEvents(prev, next []BotEvent) (added []BotEvent) {
+	seen := make(map[eventKey]int, len(prev))
+	for i := range prev {
+		seen[keyOf(&prev[i])]++
+	}
+	for i := range next {
+		key := keyOf(&next[i])
+		if seen[key] > 0 {
+			seen[key]--
+			continue
+		}
+		added = append(added, next[i])
+	}
+	return added
+}
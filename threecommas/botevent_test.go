@@ -2,10 +2,12 @@ package threecommas
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
-	"strings"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/recomma/3commas-sdk-go/threecommas/eventparser"
 	"github.com/stretchr/testify/require"
@@ -99,8 +101,8 @@ func TestGetDeal(t *testing.T) {
 
 				ctx := eventparser.Context{
 					Strategy:      DealStrategy(deal),
-					BaseCurrency:  strings.ToUpper(deal.ToCurrency),
-					QuoteCurrency: strings.ToUpper(deal.FromCurrency),
+					BaseCurrency:  deal.TradingPair().Base,
+					QuoteCurrency: deal.TradingPair().Quote,
 				}
 
 				for _, raw := range deal.BotEvents {
@@ -148,3 +150,438 @@ func TestGetDeal(t *testing.T) {
 		}
 	}
 }
+
+func TestFingerprintCryptoQuoted(t *testing.T) {
+	msg := func(s string) *string { return &s }
+	now := time.Now()
+
+	deal := Deal{
+		Status:       DealStatusBought,
+		ToCurrency:   "ada",
+		FromCurrency: "btc",
+		BotEvents: []struct {
+			CreatedAt *time.Time `json:"created_at,omitempty"`
+			Message   *string    `json:"message,omitempty"`
+		}{
+			{CreatedAt: &now, Message: msg("Placing averaging order (2 out of 5). Price: 0.00000234 BTC Size: 0.00119808 BTC (512.0 ADA)")},
+			{CreatedAt: &now, Message: msg("Placing averaging order (2 out of 5). Price: 0.00000235 BTC Size: 0.0012032 BTC (512.0 ADA)")},
+		},
+	}
+
+	events := deal.Events()
+	require.Len(t, events, 2)
+	require.InDelta(t, 0.00000234, events[0].Price, 0)
+	require.InDelta(t, 0.00000235, events[1].Price, 0)
+
+	// Fingerprints only depend on order identity and currencies, not on the
+	// (tiny) floating point amounts.
+	require.Equal(t, "Safety|2|5|ADA|BTC", events[0].Fingerprint())
+	require.Equal(t, events[0].Fingerprint(), events[1].Fingerprint())
+	require.Equal(t, events[0].FingerprintAsID(), events[1].FingerprintAsID())
+}
+
+func TestStableFingerprint(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   BotEvent
+		stable bool
+		exact  bool
+	}{
+		{
+			name:   "position without size",
+			a:      BotEvent{OrderType: MarketOrderDealOrderTypeSafety, OrderPosition: 3, Coin: "doge", QuoteCurrency: "usdt"},
+			b:      BotEvent{OrderType: MarketOrderDealOrderTypeSafety, Coin: "DOGE", QuoteCurrency: "USDT"},
+			stable: true,
+		},
+		{
+			name:   "same progress",
+			a:      BotEvent{OrderType: MarketOrderDealOrderTypeSafety, OrderPosition: 3, OrderSize: 9, Coin: "DOGE", QuoteCurrency: "USDT"},
+			b:      BotEvent{OrderType: MarketOrderDealOrderTypeSafety, OrderPosition: 3, OrderSize: 9, Coin: "doge", QuoteCurrency: "usdt"},
+			stable: true,
+			exact:  true,
+		},
+		{
+			name: "different position with size",
+			a:    BotEvent{OrderType: MarketOrderDealOrderTypeSafety, OrderPosition: 3, OrderSize: 9, Coin: "DOGE", QuoteCurrency: "USDT"},
+			b:    BotEvent{OrderType: MarketOrderDealOrderTypeSafety, OrderPosition: 4, OrderSize: 9, Coin: "DOGE", QuoteCurrency: "USDT"},
+		},
+		{
+			name: "different order type",
+			a:    BotEvent{OrderType: MarketOrderDealOrderTypeSafety, Coin: "DOGE", QuoteCurrency: "USDT"},
+			b:    BotEvent{OrderType: MarketOrderDealOrderTypeManualSafety, Coin: "DOGE", QuoteCurrency: "USDT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.stable, tt.a.StableFingerprint() == tt.b.StableFingerprint())
+			require.Equal(t, tt.exact, tt.a.Fingerprint() == tt.b.Fingerprint())
+		})
+	}
+
+	event := BotEvent{OrderType: MarketOrderDealOrderTypeSafety, OrderPosition: 2, OrderSize: 5, Coin: "ada", QuoteCurrency: "btc"}
+	require.Equal(t, event.Fingerprint(), event.StableFingerprint())
+}
+
+func TestDealEventsCache(t *testing.T) {
+	msg := func(s string) *string { return &s }
+	now := time.Now()
+
+	deal := &Deal{
+		Status:       DealStatusBought,
+		ToCurrency:   "DOGE",
+		FromCurrency: "USDT",
+		BotEvents: []struct {
+			CreatedAt *time.Time `json:"created_at,omitempty"`
+			Message   *string    `json:"message,omitempty"`
+		}{
+			{CreatedAt: &now, Message: msg("Placing base order. Price: market Size: 25.0 USDT (100.0 DOGE)")},
+		},
+	}
+
+	first := deal.Events()
+	require.Len(t, first, 1)
+
+	// Mutating the returned slice must not leak into the cache
+	first[0].Coin = "MUTATED"
+	require.Equal(t, "DOGE", deal.Events()[0].Coin)
+
+	// Appending an event invalidates the cache
+	later := now.Add(time.Minute)
+	deal.BotEvents = append(deal.BotEvents, struct {
+		CreatedAt *time.Time `json:"created_at,omitempty"`
+		Message   *string    `json:"message,omitempty"`
+	}{CreatedAt: &later, Message: msg("Base order executed. Price: 0.25 USDT. Size: 25.0 USDT (100.0 DOGE)")})
+
+	events := deal.Events()
+	require.Len(t, events, 2)
+	require.Equal(t, BotEventActionExecute, events[1].Action)
+
+	// Replacing BotEvents with a slice of the same length invalidates it too
+	deal.BotEvents = append(deal.BotEvents[:0:0], deal.BotEvents[1], deal.BotEvents[0])
+	deal.BotEvents[1].Message = msg("Placing TakeProfit trade. Price: 0.27 USDT Size: 27.0 USDT (100.0 DOGE)")
+	events = deal.Events()
+	require.Len(t, events, 2)
+	require.Equal(t, MarketOrderDealOrderTypeTakeProfit, events[0].OrderType)
+	require.Equal(t, SELL, events[0].Type)
+
+	// A status change flips the strategy the sides are inferred with
+	deal.Status = "sold"
+	events = deal.Events()
+	require.Equal(t, BUY, events[0].Type)
+	require.Equal(t, SELL, events[1].Type)
+
+	// In place edits are not detected, a currency change is
+	*deal.BotEvents[0].Message = "Base order executed"
+	require.Equal(t, "DOGE", deal.Events()[1].Coin)
+	deal.ToCurrency = "shib"
+	events = deal.Events()
+	require.Equal(t, "SHIB", events[1].Coin)
+
+	// A copy of the Deal value gets the same events
+	copied := *deal
+	require.Equal(t, events, copied.Events())
+
+	var nilDeal *Deal
+	require.Nil(t, nilDeal.Events())
+}
+
+func BenchmarkDealEvents(b *testing.B) {
+	var deal Deal
+	require.NoError(b, json.Unmarshal([]byte(exampleDeal), &deal))
+
+	b.Run("cached", func(b *testing.B) {
+		for b.Loop() {
+			_ = deal.Events()
+		}
+	})
+
+	b.Run("uncached", func(b *testing.B) {
+		for b.Loop() {
+			_ = deal.parseEvents(deal.eventContext())
+		}
+	})
+}
+
+func TestEventsInferSafetyPositions(t *testing.T) {
+	msg := func(s string) *string { return &s }
+	start := time.Now()
+	at := func(i int) *time.Time {
+		ts := start.Add(time.Duration(i) * time.Minute)
+		return &ts
+	}
+
+	deal := Deal{
+		Status:       DealStatusBought,
+		ToCurrency:   "DOGE",
+		FromCurrency: "USDT",
+		BotEvents: []struct {
+			CreatedAt *time.Time `json:"created_at,omitempty"`
+			Message   *string    `json:"message,omitempty"`
+		}{
+			{CreatedAt: at(0), Message: msg("Placing averaging order. Price: 0.23 USDT Size: 23.0 USDT (100.0 DOGE)")},
+			{CreatedAt: at(1), Message: msg("Placing averaging order. Price: 0.22 USDT Size: 22.0 USDT (100.0 DOGE)")},
+			{CreatedAt: at(2), Message: msg("Averaging order executed. Price: 0.23 USDT Size: 23.0 USDT (100.0 DOGE)")},
+			{CreatedAt: at(3), Message: msg("Placing averaging order. Price: 0.21 USDT Size: 21.0 USDT (100.0 DOGE)")},
+			{CreatedAt: at(4), Message: msg("Averaging order executed. Price: 0.22 USDT Size: 22.0 USDT (100.0 DOGE)")},
+		},
+	}
+
+	var got []string
+	for _, ev := range deal.Events() {
+		got = append(got, fmt.Sprintf("%s %s", ev.Action, ev.Fingerprint()))
+	}
+
+	require.Equal(t, []string{
+		"Placing Safety|1|0|DOGE|USDT",
+		"Placing Safety|2|0|DOGE|USDT",
+		"Execute Safety|1|0|DOGE|USDT",
+		"Placing Safety|3|0|DOGE|USDT",
+		"Execute Safety|2|0|DOGE|USDT",
+	}, got)
+}
+
+func TestEventsTiedTimestamps(t *testing.T) {
+	msg := func(s string) *string { return &s }
+	start := time.Now().Truncate(time.Second)
+
+	// Newest first with pairs of events in the same second, more events than
+	// the sort handles by insertion
+	deal := Deal{
+		Status:       DealStatusBought,
+		ToCurrency:   "DOGE",
+		FromCurrency: "USDT",
+	}
+	const pairs = 10
+	var want []float64
+	for i := pairs - 1; i >= 0; i-- {
+		ts := start.Add(time.Duration(i) * time.Second)
+		for j := range 2 {
+			price := float64(100+10*i+j) / 1000
+			deal.BotEvents = append(deal.BotEvents, struct {
+				CreatedAt *time.Time `json:"created_at,omitempty"`
+				Message   *string    `json:"message,omitempty"`
+			}{CreatedAt: &ts, Message: msg(fmt.Sprintf("Placing averaging order. Price: %g USDT Size: 10.0 USDT (100.0 DOGE)", price))})
+		}
+	}
+	for i := range pairs {
+		want = append(want, float64(100+10*i)/1000, float64(100+10*i+1)/1000)
+	}
+
+	events := deal.Events()
+	require.Len(t, events, len(want))
+	for i, ev := range events {
+		require.Equal(t, i, ev.SequenceIndex)
+		require.Equal(t, i+1, ev.OrderPosition)
+		require.InDelta(t, want[i], ev.Price, 1e-9, "event %d keeps the API order of its second", i)
+	}
+}
+
+func TestDealEventsByTypeAndAction(t *testing.T) {
+	var deal Deal
+	require.NoError(t, json.Unmarshal([]byte(exampleDeal), &deal))
+
+	safety := deal.EventsByType(MarketOrderDealOrderTypeSafety)
+	require.Len(t, safety, 18)
+	for _, ev := range safety {
+		require.Equal(t, MarketOrderDealOrderTypeSafety, ev.OrderType)
+	}
+	require.Len(t, deal.EventsByType(MarketOrderDealOrderTypeTakeProfit), 29)
+	require.Empty(t, deal.EventsByType(MarketOrderDealOrderTypeStopLoss))
+
+	executed := deal.EventsByAction(BotEventActionExecute)
+	require.Len(t, executed, 10)
+	for _, ev := range executed {
+		require.Equal(t, BotEventActionExecute, ev.Action)
+	}
+	require.Equal(t, executed, Filter(deal.Events(), BotEventFilterAction(BotEventActionExecute)))
+
+	require.Empty(t, (*Deal)(nil).EventsByAction(BotEventActionPlace))
+}
+
+func TestEventsSequenceIndex(t *testing.T) {
+	var deal Deal
+	require.NoError(t, json.Unmarshal([]byte(exampleDeal), &deal))
+	events := deal.Events()
+	require.NotEmpty(t, events)
+
+	fingerprints := make(map[string][]int)
+	for i, ev := range events {
+		require.Equal(t, i, ev.SequenceIndex)
+		if i > 0 {
+			require.False(t, ev.CreatedAt.Before(events[i-1].CreatedAt))
+		}
+		key := fmt.Sprintf("%s %s", ev.Action, ev.Fingerprint())
+		fingerprints[key] = append(fingerprints[key], ev.SequenceIndex)
+	}
+
+	// Identical fingerprints, e.g. of take profits, get distinct indices
+	var shared bool
+	for _, indices := range fingerprints {
+		if len(indices) > 1 {
+			shared = true
+			require.Less(t, indices[0], indices[1])
+		}
+	}
+	require.True(t, shared, "the fixture has events that share a fingerprint")
+}
+
+func TestDedupeConsecutive(t *testing.T) {
+	var deal Deal
+	require.NoError(t, json.Unmarshal([]byte(exampleDeal), &deal))
+	events := deal.Events()
+
+	// The fixture's TP churn cycles through Cancel, Cancelled and Placing, so
+	// none of it is a consecutive duplicate.
+	require.Equal(t, events, DedupeConsecutive(events))
+
+	// Replay the re-placed take profit after each safety order fill
+	var replayed []BotEvent
+	for _, ev := range events {
+		replayed = append(replayed, ev)
+		if ev.OrderType == MarketOrderDealOrderTypeTakeProfit && ev.Action == BotEventActionPlace {
+			for i := 1; i <= 3; i++ {
+				dup := ev
+				dup.CreatedAt = ev.CreatedAt.Add(time.Duration(i) * time.Second)
+				replayed = append(replayed, dup)
+			}
+		}
+	}
+	require.Greater(t, len(replayed), len(events))
+
+	deduped := DedupeConsecutive(replayed)
+	require.Equal(t, events, deduped, "the first occurrence and its timestamp must be kept")
+
+	// A changed status is a new state, even with the same fingerprint
+	placed := events[2]
+	filled := placed
+	filled.Status = Filled
+	require.Len(t, DedupeConsecutive([]BotEvent{placed, filled, filled}), 2)
+
+	require.Nil(t, DedupeConsecutive(nil))
+}
+
+func TestDiffEvents(t *testing.T) {
+	var deal Deal
+	require.NoError(t, json.Unmarshal([]byte(exampleDeal), &deal))
+	events := deal.Events()
+
+	// An earlier poll only saw the first part of the deal
+	prev := events[:20]
+	added := DiffEvents(prev, events)
+	require.Equal(t, events[20:], added)
+
+	require.Empty(t, DiffEvents(events, events))
+	require.Equal(t, events, DiffEvents(nil, events))
+	require.Empty(t, DiffEvents(events, nil))
+
+	// Same fingerprint and action but a later timestamp is a new event
+	replaced := events[2]
+	replaced.CreatedAt = replaced.CreatedAt.Add(time.Minute)
+	require.Equal(t, []BotEvent{replaced}, DiffEvents(events, append(slices.Clone(events), replaced)))
+
+	// Duplicates are matched one to one
+	dup := []BotEvent{events[0], events[0]}
+	require.Equal(t, []BotEvent{events[0]}, DiffEvents(dup[:1], dup))
+}
+
+func TestBotEventAsMarketOrder(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		event BotEvent
+		want  MarketOrder
+	}{
+		{
+			name: "base",
+			event: BotEvent{
+				CreatedAt:     createdAt,
+				Action:        BotEventActionExecute,
+				Coin:          "DOGE",
+				Type:          BUY,
+				Status:        Filled,
+				Price:         0.22787,
+				Size:          110,
+				OrderType:     MarketOrderDealOrderTypeBase,
+				OrderSize:     1,
+				OrderPosition: 1,
+				QuoteVolume:   25.0654404,
+				QuoteCurrency: "USDT",
+			},
+			want: MarketOrder{
+				OrderType:     BUY,
+				DealOrderType: MarketOrderDealOrderTypeBase,
+				StatusString:  Filled,
+				Rate:          "0.22787",
+				Quantity:      "110",
+				Total:         "25.0654404",
+				CreatedAt:     createdAt,
+			},
+		},
+		{
+			name: "take profit",
+			event: BotEvent{
+				CreatedAt:     createdAt,
+				Action:        BotEventActionPlace,
+				Coin:          "DOGE",
+				Type:          SELL,
+				Status:        Active,
+				Price:         0.2301,
+				Size:          110,
+				OrderType:     MarketOrderDealOrderTypeTakeProfit,
+				OrderSize:     1,
+				OrderPosition: 1,
+				QuoteVolume:   25.311,
+				QuoteCurrency: "USDT",
+			},
+			want: MarketOrder{
+				OrderType:     SELL,
+				DealOrderType: MarketOrderDealOrderTypeTakeProfit,
+				StatusString:  Active,
+				Rate:          "0.2301",
+				Quantity:      "110",
+				Total:         "25.311",
+				CreatedAt:     createdAt,
+			},
+		},
+		{
+			name: "cancelling",
+			event: BotEvent{
+				CreatedAt:     createdAt,
+				Action:        BotEventActionCancel,
+				Coin:          "DOGE",
+				Type:          BUY,
+				Status:        MarketOrderStatusString(eventparser.StatusCancelling),
+				Price:         0.21,
+				Size:          120,
+				OrderType:     MarketOrderDealOrderTypeSafety,
+				OrderSize:     2,
+				OrderPosition: 1,
+				QuoteVolume:   25.2,
+				QuoteCurrency: "USDT",
+			},
+			want: MarketOrder{
+				OrderType:     BUY,
+				DealOrderType: MarketOrderDealOrderTypeSafety,
+				StatusString:  Active,
+				Rate:          "0.21",
+				Quantity:      "120",
+				Total:         "25.2",
+				CreatedAt:     createdAt,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.OrderId = fmt.Sprint(tt.event.FingerprintAsID())
+			got := tt.event.AsMarketOrder()
+			require.Equal(t, tt.want, got)
+			require.True(t, got.StatusString.Valid())
+		})
+	}
+
+	base, tp := tests[0].event.AsMarketOrder(), tests[1].event.AsMarketOrder()
+	require.NotEqual(t, base.OrderId, tp.OrderId)
+}
@@ -63,3 +63,28 @@ func TestMultipleClientOptions(t *testing.T) {
 	// Verify multiple client options were stored
 	require.Len(t, client.clientOptions, 2, "expected two client options")
 }
+
+func TestWithThreeCommasBaseURLFromEnv(t *testing.T) {
+	const envKey = "THREECOMMAS_TEST_BASE_URL"
+
+	t.Run("set", func(t *testing.T) {
+		t.Setenv(envKey, "http://localhost:8080/public/api")
+		client, err := New3CommasClient(append(defaultTestOptions(), WithThreeCommasBaseURLFromEnv(envKey))...)
+		require.NoError(t, err)
+		require.Equal(t, "http://localhost:8080/public/api", client.baseURL)
+	})
+
+	t.Run("unset is a no-op", func(t *testing.T) {
+		t.Setenv(envKey, "")
+		client, err := New3CommasClient(append(defaultTestOptions(),
+			WithThreeCommasBaseURL("https://example.test/api"),
+			WithThreeCommasBaseURLFromEnv(envKey),
+		)...)
+		require.NoError(t, err)
+		require.Equal(t, "https://example.test/api", client.baseURL)
+
+		client, err = New3CommasClient(append(defaultTestOptions(), WithThreeCommasBaseURLFromEnv(envKey))...)
+		require.NoError(t, err)
+		require.Equal(t, "https://api.3commas.io/public/api", client.baseURL)
+	})
+}
@@ -0,0 +1,37 @@
+package threecommas
+
+import "time"
+
+// Clock abstracts the passing of time for the time-dependent parts of the
+// client (rate limiting, backoff), allowing deterministic tests.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the subset of *time.Timer used by the client.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// WithClock sets the Clock used by the client. Defaults to the real clock.
+func WithClock(clock Clock) ThreeCommasClientOption {
+	return rateLimitOption(func(c *ThreeCommasClient) {
+		c.clock = clock
+	})
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (r realTimer) C() <-chan time.Time { return r.t.C }
+
+func (r realTimer) Stop() bool { return r.t.Stop() }
@@ -0,0 +1,172 @@
+package threecommas
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced Clock for deterministic tests.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock    *fakeClock
+	deadline time.Time
+	c        chan time.Time
+	stopped  bool
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward and fires all timers that became due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.stopped {
+			continue
+		}
+		if !t.deadline.After(c.now) {
+			t.c <- c.now
+			continue
+		}
+		pending = append(pending, t)
+	}
+	c.timers = pending
+}
+
+// WaitForTimers blocks until n timers are pending, i.e. until the code under
+// test is sleeping.
+func (c *fakeClock) WaitForTimers(t *testing.T, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		active := 0
+		for _, timer := range c.timers {
+			if !timer.stopped {
+				active++
+			}
+		}
+		return active >= n
+	}, time.Second, time.Millisecond)
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
+
+func TestFixedWindowLimiterWithFakeClock(t *testing.T) {
+	clock := newFakeClock(time.Date(2025, 8, 4, 12, 30, 10, 0, time.UTC))
+	limiter := newFixedWindowLimiter(time.Minute, 1)
+	limiter.clock = clock
+
+	require.NoError(t, limiter.Wait(context.Background()))
+
+	done := make(chan error, 1)
+	go func() { done <- limiter.Wait(context.Background()) }()
+
+	// The second request has to wait for the 12:31:00 window
+	clock.WaitForTimers(t, 1)
+	select {
+	case <-done:
+		t.Fatal("Wait returned before the window rolled over")
+	default:
+	}
+
+	clock.Advance(50 * time.Second)
+	require.NoError(t, <-done)
+	require.Equal(t, time.Date(2025, 8, 4, 12, 31, 0, 0, time.UTC), limiter.windowStart)
+}
+
+func TestBackoffWithFakeClock(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2025, 8, 4, 12, 30, 10, 0, time.UTC))
+	client, err := New3CommasClient(
+		WithAPIKey("test-key"),
+		WithPrivatePEM([]byte(fakeKey)),
+		WithThreeCommasBaseURL(server.URL),
+		WithClock(clock),
+	)
+	require.NoError(t, err)
+
+	resp, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode())
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+		done <- err
+	}()
+
+	// Blocked by the Retry-After backoff until the fake clock passes it
+	clock.WaitForTimers(t, 1)
+	clock.Advance(119 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("request went through before the backoff expired")
+	default:
+	}
+
+	clock.Advance(time.Second)
+	require.NoError(t, <-done)
+	require.Equal(t, 2, requests)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 8, 4, 12, 30, 0, 0, time.UTC)
+	require.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	require.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	require.Zero(t, parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	require.Zero(t, parseRetryAfter("", now))
+	require.Zero(t, parseRetryAfter("soon", now))
+}
@@ -0,0 +1,101 @@
+package threecommas
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCredentialsFile(t *testing.T) {
+	jsonCreds, err := json.Marshal(credentialsJSON{APIKey: "filekey", PrivateKey: fakeKey})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		content string
+	}{
+		{name: "key line and pem", content: "filekey\n" + fakeKey},
+		{name: "surrounding blank lines", content: "\n  filekey  \n\n" + fakeKey + "\n\n"},
+		{name: "json", content: string(jsonCreds)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "credentials")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			var apiKey string
+			client, err := New3CommasClient(
+				WithCredentialsFile(path),
+				withHTTPClient(doerFunc(func(req *http.Request) (*http.Response, error) {
+					apiKey = req.Header.Get("Apikey")
+					return okResponse(`{"id": 123}`), nil
+				})),
+			)
+			require.NoError(t, err)
+
+			_, err = client.GetDealForID(context.Background(), 123)
+			require.NoError(t, err)
+			require.Equal(t, "filekey", apiKey)
+		})
+	}
+}
+
+func TestWithCredentialsFileInvalid(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "empty", content: "", wantErr: "missing API key"},
+		{name: "pem only", content: fakeKey, wantErr: "missing API key"},
+		{name: "key only", content: "filekey\n", wantErr: "missing PEM block"},
+		{name: "malformed json", content: `{"api_key": "filekey"`, wantErr: "invalid JSON"},
+		{name: "json without key", content: `{"private_key": "x"}`, wantErr: "missing API key"},
+		{name: "json without pem", content: `{"api_key": "filekey"}`, wantErr: "missing PEM block"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "credentials")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			_, err := New3CommasClient(WithCredentialsFile(path))
+			require.ErrorContains(t, err, tc.wantErr)
+			require.ErrorContains(t, err, path)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := New3CommasClient(WithCredentialsFile(filepath.Join(t.TempDir(), "missing")))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestValidateKeyPair(t *testing.T) {
+	require.NoError(t, ValidateKeyPair([]byte(fakeKey), []byte(fakePublic)))
+
+	other, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	pkcs1Public := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&other.PublicKey)})
+	pkcs8Private, err := x509.MarshalPKCS8PrivateKey(other)
+	require.NoError(t, err)
+	otherPrivate := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Private})
+
+	require.NoError(t, ValidateKeyPair(otherPrivate, pkcs1Public))
+	require.Error(t, ValidateKeyPair([]byte(fakeKey), pkcs1Public))
+	require.Error(t, ValidateKeyPair(otherPrivate, []byte(fakePublic)))
+
+	require.Error(t, ValidateKeyPair([]byte(fakeKey), []byte("not a pem")))
+	require.Error(t, ValidateKeyPair([]byte(fakePublic), []byte(fakePublic)))
+	require.Error(t, ValidateKeyPair([]byte(fakeKey), []byte(fakeKey)))
+}
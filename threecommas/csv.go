@@ -0,0 +1,58 @@
+package threecommas
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+var dealsCSVHeader = []string{"id", "bot_id", "pair", "status", "created_at", "closed_at", "realized_profit"}
+
+// WriteDealsCSV writes the deals to w as CSV, with a header and one row per
+// deal. Times are written as RFC 3339 in UTC. The closed_at and
+// realized_profit columns are empty while the deal is open or when the API
+// didn't report them (see RealizedProfit).
+func WriteDealsCSV(w io.Writer, deals []Deal) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(dealsCSVHeader); err != nil {
+		return fmt.Errorf("write deals csv: %w", err)
+	}
+	for i := range deals {
+		if err := cw.Write(dealCSVRecord(&deals[i])); err != nil {
+			return fmt.Errorf("write deals csv: deal %d: %w", deals[i].Id, err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write deals csv: %w", err)
+	}
+	return nil
+}
+
+func dealCSVRecord(d *Deal) []string {
+	var closedAt, profit string
+	if t, err := d.ClosedAt.Get(); err == nil {
+		closedAt = formatCSVTime(t)
+	}
+	if p, ok := d.RealizedProfit(); ok {
+		profit = strconv.FormatFloat(p, 'f', -1, 64)
+	}
+	return []string{
+		strconv.Itoa(d.Id),
+		strconv.Itoa(d.BotId),
+		d.Pair,
+		string(d.Status),
+		formatCSVTime(d.CreatedAt),
+		closedAt,
+		profit,
+	}
+}
+
+func formatCSVTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,62 @@
+package threecommas
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oapi-codegen/nullable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDealsCSV(t *testing.T) {
+	created := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	deals := []Deal{
+		{
+			Id:          1,
+			BotId:       42,
+			Pair:        "USDT_DOGE",
+			Status:      DealStatusCompleted,
+			CreatedAt:   created,
+			ClosedAt:    nullable.NewNullableWithValue(created.Add(5 * time.Hour)),
+			FinalProfit: "4.53711258",
+		},
+		{
+			Id:          2,
+			BotId:       42,
+			Pair:        "USDT_BTC",
+			Status:      DealStatusBought,
+			CreatedAt:   created,
+			ClosedAt:    nullable.NewNullNullable[time.Time](),
+			FinalProfit: "1.5", // not realized while open
+		},
+		{Id: 3},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteDealsCSV(&buf, deals))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"id", "bot_id", "pair", "status", "created_at", "closed_at", "realized_profit"},
+		{"1", "42", "USDT_DOGE", "completed", "2025-03-01T12:30:00Z", "2025-03-01T17:30:00Z", "4.53711258"},
+		{"2", "42", "USDT_BTC", "bought", "2025-03-01T12:30:00Z", "", ""},
+		{"3", "0", "", "", "", "", ""},
+	}, records)
+
+	buf.Reset()
+	require.NoError(t, WriteDealsCSV(&buf, nil))
+	require.Equal(t, "id,bot_id,pair,status,created_at,closed_at,realized_profit\n", buf.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWriteDealsCSVError(t *testing.T) {
+	err := WriteDealsCSV(failingWriter{}, []Deal{{Id: 1}})
+	require.ErrorContains(t, err, "disk full")
+}
@@ -0,0 +1,155 @@
+package threecommas
+
+import (
+	"strconv"
+	"strings"
+)
+
+// IsTerminal reports whether the deal reached a final state and will not
+// change anymore. Next to the statuses known to the OpenAPI spec, it also
+// recognises the final statuses 3commas returns in practice.
+func (s DealStatus) IsTerminal() bool {
+	switch s {
+	case DealStatusCompleted, DealStatusFailed,
+		"cancelled", "panic_sold", "stop_loss_finished", "liquidated":
+		return true
+	default:
+		return false
+	}
+}
+
+// RealizedProfit returns the final profit of the deal in its profit currency.
+// The profit is only known (ok is true) once the deal reached a terminal
+// status and the API reported a parseable final profit.
+func (d *Deal) RealizedProfit() (profit float64, ok bool) {
+	if d == nil || !d.Status.IsTerminal() {
+		return 0, false
+	}
+	return parseDealFloat(d.FinalProfit)
+}
+
+// IsProfitable reports whether the deal closed with a positive RealizedProfit.
+// known is false while the realized profit is not known, e.g. for open deals.
+func (d *Deal) IsProfitable() (profitable, known bool) {
+	profit, ok := d.RealizedProfit()
+	if !ok {
+		return false, false
+	}
+	return profit > 0, true
+}
+
+// The accessors below read the bot settings the deal was opened with. Where
+// the generated Deal already has a field of the same name, the accessor uses a
+// different name. All of them are safe to call on a nil Deal, ok is false when
+// the setting is missing or not parseable.
+
+// TakeProfitPercentage returns the take profit percentage of the deal.
+func (d *Deal) TakeProfitPercentage() (pct float64, ok bool) {
+	if d == nil {
+		return 0, false
+	}
+	tp, err := d.TakeProfit.Get()
+	if err != nil {
+		return 0, false
+	}
+	return parseDealFloat(tp)
+}
+
+// StopLoss returns the stop loss percentage of the deal. A stop loss is only
+// configured (ok is true) when the percentage is above zero.
+func (d *Deal) StopLoss() (pct float64, ok bool) {
+	if d == nil {
+		return 0, false
+	}
+	pct, ok = parseDealFloat(d.StopLossPercentage)
+	if !ok || pct <= 0 {
+		return 0, false
+	}
+	return pct, true
+}
+
+// MaxSafetyOrderCount returns the maximum number of safety orders of the deal.
+func (d *Deal) MaxSafetyOrderCount() int {
+	if d == nil {
+		return 0
+	}
+	return d.MaxSafetyOrders
+}
+
+// SafetyOrderStep returns the price deviation percentage to open the first
+// safety order.
+func (d *Deal) SafetyOrderStep() (pct float64, ok bool) {
+	if d == nil {
+		return 0, false
+	}
+	return parseDealFloat(d.SafetyOrderStepPercentage)
+}
+
+// SafetyOrderSize returns the volume of the first safety order.
+func (d *Deal) SafetyOrderSize() (volume float64, ok bool) {
+	if d == nil {
+		return 0, false
+	}
+	return parseDealFloat(d.SafetyOrderVolume)
+}
+
+// MartingaleVolumeScale returns the martingale volume coefficient of the deal.
+func (d *Deal) MartingaleVolumeScale() (scale float64, ok bool) {
+	if d == nil {
+		return 0, false
+	}
+	return parseDealFloat(d.MartingaleVolumeCoefficient)
+}
+
+// MartingaleStepScale returns the martingale step coefficient of the deal.
+func (d *Deal) MartingaleStepScale() (scale float64, ok bool) {
+	if d == nil {
+		return 0, false
+	}
+	return parseDealFloat(d.MartingaleStepCoefficient)
+}
+
+// Pair is a trading pair of a base and a quote currency, e.g. DOGE and USDT.
+type Pair struct {
+	Base  string
+	Quote string
+}
+
+// String returns the pair in 3Commas format, "QUOTE_BASE", e.g. "USDT_DOGE".
+func (p Pair) String() string {
+	return p.Quote + "_" + p.Base
+}
+
+// TradingPair returns the upper cased pair of the deal, the base is the
+// ToCurrency and the quote the FromCurrency. It is named TradingPair as the
+// generated Deal already has a Pair field with the pair in 3Commas format.
+func (d *Deal) TradingPair() Pair {
+	if d == nil {
+		return Pair{}
+	}
+	return Pair{
+		Base:  strings.ToUpper(d.ToCurrency),
+		Quote: strings.ToUpper(d.FromCurrency),
+	}
+}
+
+// BoughtQuoteVolume returns the volume spent on buys so far, in the quote
+// currency (FromCurrency) of the deal.
+func (d *Deal) BoughtQuoteVolume() (volume float64, ok bool) {
+	if d == nil {
+		return 0, false
+	}
+	return parseDealFloat(d.BoughtVolume)
+}
+
+// parseDealFloat parses one of the deal's decimal string fields.
+func parseDealFloat(v string) (float64, bool) {
+	if v == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
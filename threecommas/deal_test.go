@@ -0,0 +1,147 @@
+package threecommas
+
+import (
+	"testing"
+
+	"github.com/oapi-codegen/nullable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDealStatusIsTerminal(t *testing.T) {
+	tests := []struct {
+		status DealStatus
+		want   bool
+	}{
+		{DealStatusBought, false},
+		{DealStatusCompleted, true},
+		{DealStatusFailed, true},
+		{"created", false},
+		{"base_order_placed", false},
+		{"cancelled", true},
+		{"panic_sold", true},
+		{"stop_loss_finished", true},
+		{"liquidated", true},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			require.Equal(t, tt.want, tt.status.IsTerminal())
+		})
+	}
+}
+
+func TestDealRealizedProfit(t *testing.T) {
+	tests := []struct {
+		name   string
+		deal   *Deal
+		want   float64
+		wantOk bool
+	}{
+		{name: "nil deal"},
+		{name: "open deal", deal: &Deal{Status: DealStatusBought, FinalProfit: "1.5"}},
+		{name: "completed deal", deal: &Deal{Status: DealStatusCompleted, FinalProfit: "1.5"}, want: 1.5, wantOk: true},
+		{name: "losing deal", deal: &Deal{Status: "stop_loss_finished", FinalProfit: "-2.25"}, want: -2.25, wantOk: true},
+		{name: "missing profit", deal: &Deal{Status: DealStatusCompleted}},
+		{name: "malformed profit", deal: &Deal{Status: DealStatusCompleted, FinalProfit: "n/a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.deal.RealizedProfit()
+			require.Equal(t, tt.wantOk, ok)
+			require.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
+func TestDealIsProfitable(t *testing.T) {
+	tests := []struct {
+		name           string
+		deal           *Deal
+		wantProfitable bool
+		wantKnown      bool
+	}{
+		{name: "nil deal"},
+		{name: "profitable deal", deal: &Deal{Status: DealStatusCompleted, FinalProfit: "1.5"}, wantProfitable: true, wantKnown: true},
+		{name: "losing deal", deal: &Deal{Status: "stop_loss_finished", FinalProfit: "-2.25"}, wantKnown: true},
+		{name: "break even deal", deal: &Deal{Status: DealStatusCompleted, FinalProfit: "0"}, wantKnown: true},
+		{name: "open deal", deal: &Deal{Status: DealStatusBought, FinalProfit: "1.5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profitable, known := tt.deal.IsProfitable()
+			require.Equal(t, tt.wantProfitable, profitable)
+			require.Equal(t, tt.wantKnown, known)
+		})
+	}
+}
+
+func TestDealSettingsAccessors(t *testing.T) {
+	full := &Deal{
+		TakeProfit:                  nullable.NewNullableWithValue("1.5"),
+		StopLossPercentage:          "5.0",
+		MaxSafetyOrders:             9,
+		SafetyOrderStepPercentage:   "2.5",
+		SafetyOrderVolume:           "25.0",
+		MartingaleVolumeCoefficient: "1.05",
+		MartingaleStepCoefficient:   "1.1",
+		BoughtVolume:                "250.5",
+	}
+	empty := &Deal{
+		TakeProfit:         nullable.NewNullNullable[string](),
+		StopLossPercentage: "0.0",
+	}
+	malformed := &Deal{
+		TakeProfit:                nullable.NewNullableWithValue("n/a"),
+		SafetyOrderStepPercentage: "two",
+	}
+
+	type accessor func(*Deal) (float64, bool)
+	accessors := map[string]accessor{
+		"TakeProfitPercentage":  (*Deal).TakeProfitPercentage,
+		"StopLoss":              (*Deal).StopLoss,
+		"SafetyOrderStep":       (*Deal).SafetyOrderStep,
+		"SafetyOrderSize":       (*Deal).SafetyOrderSize,
+		"MartingaleVolumeScale": (*Deal).MartingaleVolumeScale,
+		"MartingaleStepScale":   (*Deal).MartingaleStepScale,
+		"BoughtQuoteVolume":     (*Deal).BoughtQuoteVolume,
+	}
+
+	want := map[string]float64{
+		"TakeProfitPercentage":  1.5,
+		"StopLoss":              5,
+		"SafetyOrderStep":       2.5,
+		"SafetyOrderSize":       25,
+		"MartingaleVolumeScale": 1.05,
+		"MartingaleStepScale":   1.1,
+		"BoughtQuoteVolume":     250.5,
+	}
+
+	for name, get := range accessors {
+		t.Run(name, func(t *testing.T) {
+			got, ok := get(full)
+			require.True(t, ok)
+			require.InDelta(t, want[name], got, 1e-9)
+
+			for _, d := range []*Deal{nil, {}, empty, malformed} {
+				got, ok := get(d)
+				require.False(t, ok)
+				require.Zero(t, got)
+			}
+		})
+	}
+
+	require.Equal(t, 9, full.MaxSafetyOrderCount())
+	require.Zero(t, (*Deal)(nil).MaxSafetyOrderCount())
+}
+
+func TestDealTradingPair(t *testing.T) {
+	deal := &Deal{FromCurrency: "usdt", ToCurrency: "doge", Pair: "USDT_DOGE"}
+	pair := deal.TradingPair()
+	require.Equal(t, Pair{Base: "DOGE", Quote: "USDT"}, pair)
+	require.Equal(t, deal.Pair, pair.String())
+
+	require.Zero(t, (*Deal)(nil).TradingPair())
+}
@@ -0,0 +1,57 @@
+package threecommas
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// errorBodyDoer keeps malformed error bodies away from the generated response
+// parsers. These decode every JSON error response into an ErrorResponse and
+// fail the whole request when that fails, losing the status and the body. An
+// error response labelled as JSON that is not an ErrorResponse, e.g. truncated
+// JSON, is relabelled as text/plain so GetErrorFromResponse reports it as an
+// *APIError with its RawBody.
+type errorBodyDoer struct {
+	base HttpRequestDoer
+}
+
+func (d *errorBodyDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.base.Do(req)
+	if err != nil || resp == nil || resp.Body == nil {
+		return resp, err
+	}
+	if 200 <= resp.StatusCode && resp.StatusCode <= 299 ||
+		!strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		return resp, nil
+	}
+
+	// Only the part kept as RawBody is checked, larger bodies are left alone
+	head, readErr := io.ReadAll(io.LimitReader(resp.Body, maxRawBodySize+1))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+	if readErr != nil || len(head) > maxRawBodySize {
+		return resp, nil
+	}
+	if parseErrorResponse(head) == nil {
+		resp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	return resp, nil
+}
+
+// withErrorBodyDoer wraps the current Doer of the client with an
+// errorBodyDoer. It is installed right after the HTTP client, below the body
+// limit, as it reads at most maxRawBodySize bytes itself.
+func withErrorBodyDoer() ClientOption {
+	return func(c *Client) error {
+		base := c.Client
+		if base == nil {
+			base = &http.Client{}
+		}
+		c.Client = &errorBodyDoer{base: base}
+		return nil
+	}
+}
@@ -0,0 +1,36 @@
+package eventparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// rawBotEvent is an entry of a deal's bot_events array.
+type rawBotEvent struct {
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ParseBotEventsJSON parses the bot_events array of a deal, as returned by the
+// 3commas API, without going through the SDK's Deal type. The events keep the
+// order of raw and carry their CreatedAt. Entries without a message are
+// skipped.
+func ParseBotEventsJSON(raw []byte, ctx Context) ([]Event, error) {
+	var entries []rawBotEvent
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("eventparser: decode bot events: %w", err)
+	}
+
+	events := make([]Event, 0, len(entries))
+	for _, entry := range entries {
+		event, err := Parse(entry.Message, ctx)
+		if err != nil {
+			// ErrEmptyMessage, the entry has no message
+			continue
+		}
+		event.CreatedAt = entry.CreatedAt
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,68 @@
+package eventparser
+
+import (
+	"testing"
+	"time"
+)
+
+// trimmedBotEvents is the start of the bot_events of the exampleDeal in the
+// threecommas package, with an entry without message.
+const trimmedBotEvents = `[
+	{
+		"message": "Averaging order (7 out of 9) executed. Price: 0.22446424 USDT Size: 24.01767368 USDT (107.0 DOGE)",
+		"created_at": "2025-09-25T20:34:48.398Z"
+	},
+	{
+		"message": "Cancelling TakeProfit trade. Price: 0.2311 USDT Size: 171.4762 USDT (742.0 DOGE)",
+		"created_at": "2025-09-25T20:34:48.523Z"
+	},
+	{
+		"created_at": "2025-09-25T20:34:48.530Z"
+	},
+	{
+		"message": "TakeProfit trade cancelled. Price: 0.2311 USDT Size: 171.4762 USDT (742.0 DOGE)",
+		"created_at": "2025-09-25T20:34:48.537Z"
+	},
+	{
+		"message": "Placing TakeProfit trade.  Price: 0.23086 USDT Size: 196.00014 USDT (849.0 DOGE), the price should rise for 2.96% to close the trade",
+		"created_at": "2025-09-25T20:34:48.600Z"
+	}
+]`
+
+func TestParseBotEventsJSON(t *testing.T) {
+	ctx := Context{Strategy: StrategyLong, BaseCurrency: "DOGE", QuoteCurrency: "USDT"}
+
+	events, err := ParseBotEventsJSON([]byte(trimmedBotEvents), ctx)
+	if err != nil {
+		t.Fatalf("ParseBotEventsJSON() error = %v", err)
+	}
+
+	want := []struct {
+		action    Action
+		orderType OrderType
+		size      float64
+		createdAt string
+	}{
+		{ActionExecute, OrderTypeSafety, 107, "2025-09-25T20:34:48.398Z"},
+		{ActionCancel, OrderTypeTakeProfit, 742, "2025-09-25T20:34:48.523Z"},
+		{ActionCancelled, OrderTypeTakeProfit, 742, "2025-09-25T20:34:48.537Z"},
+		{ActionPlace, OrderTypeTakeProfit, 849, "2025-09-25T20:34:48.600Z"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("ParseBotEventsJSON() returned %d events, want %d", len(events), len(want))
+	}
+	for i, w := range want {
+		got := events[i]
+		createdAt, _ := time.Parse(time.RFC3339, w.createdAt)
+		if got.Action != w.action || got.OrderType != w.orderType || got.Size != w.size || !got.CreatedAt.Equal(createdAt) {
+			t.Fatalf("event %d = %v %v %v %v, want %v %v %v %v", i, got.Action, got.OrderType, got.Size, got.CreatedAt, w.action, w.orderType, w.size, createdAt)
+		}
+		if got.Coin != "DOGE" {
+			t.Fatalf("event %d Coin = %q, want DOGE", i, got.Coin)
+		}
+	}
+
+	if _, err := ParseBotEventsJSON([]byte(`{"message": "not an array"}`), ctx); err == nil {
+		t.Fatalf("ParseBotEventsJSON() on an object: expected an error")
+	}
+}
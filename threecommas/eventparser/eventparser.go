@@ -5,18 +5,37 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
 var (
 	progressRe       = regexp.MustCompile(`\((\d+)\s+out of\s+(\d+)\)`)
-	priceRe          = regexp.MustCompile(`Price:\s*(market|[\d.]+)(?:\s+([A-Za-z]{2,}))?`)
-	sizeRe           = regexp.MustCompile(`Size:\s*([\d.]+)\s*([A-Za-z]{2,})`)
-	baseSizeRe       = regexp.MustCompile(`\((?:[A-Za-z]+\s+)?([\d.]+)\s*([A-Za-z]{2,})\)`)
-	profitRe         = regexp.MustCompile(`Profit:\s*([+-]?\d+(?:\.\d+)?)\s*([A-Za-z]{2,})`)
-	profitUSDRe      = regexp.MustCompile(`\(([+-]?\d+(?:\.\d+)?)\s*\$\)`)
-	profitPctRe      = regexp.MustCompile(`\(([+-]?\d+(?:\.\d+)?)%\s*`) // matches “(2.0% …”
-	amountCurrencyRe = regexp.MustCompile(`([-+]?\d+(?:\.\d+)?)\s*([A-Za-z]{2,})`)
+	rangeRe          = regexp.MustCompile(`(?i)\((\d+)\s*(?:-|to)\s*(\d+)\)`) // “(1-9)” or “(1 to 9)”
+	priceRe          = regexp.MustCompile(`Price:\s*((?i:market)|-?[\d.]+(?:[eE][+-]?\d+)?)(?:\s+([A-Za-z]{2,})\b(?:[^:]|$))?`)
+	sizeRe           = regexp.MustCompile(`Size:\s*(-?[\d.]+(?:[eE][+-]?\d+)?)\s*([A-Za-z]{2,})`)
+	baseSizeRe       = regexp.MustCompile(`\((?:([A-Za-z]+(?:\s+[A-Za-z]+)*):?\s+)?(-?[\d.]+(?:[eE][+-]?\d+)?)\s*([A-Za-z]{2,})\)`)
+	profitRe         = regexp.MustCompile(`Profit:\s*([+-]?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)\s*([A-Za-z]{2,})`)
+	profitCurFirstRe = regexp.MustCompile(`Profit:\s*([A-Za-z]{2,})\s*([+-]?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)`) // “Profit: USDT +4.53”
+	profitUSDRe      = regexp.MustCompile(`\(([+-]?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)\s*\$\)`)
+	profitPctRe      = regexp.MustCompile(`(?i)\(([+-]?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)\s*%\s*(?:from|of)\s+(?:the\s+)?total volume\)`) // “(2.0% from total volume)”
+	amountCurrencyRe = regexp.MustCompile(`([-+]?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)\s*([A-Za-z]{2,})`)
+	riskReductionRe  = regexp.MustCompile(`(?i)Risk reduction:?\s*(\d+(?:\.\d+)?)\s*(%|[A-Za-z]{2,})`)
+	durationRe       = regexp.MustCompile(`(?i)(?:about\s+)?\b(\d+|an?)\s+(minute|hour|day)s?\b`) // “#profit about 5 hours”
+	reduceOnlyRe     = regexp.MustCompile(`(?i)\s*\(?\breduce[- ]?only\b\)?`)                     // “reduce-only”, “(ReduceOnly)”
+	// tpStepPctRe matches the share of a split take profit step, either right
+	// after its progress, “(1 out of 3) 50%”, or as “50% of the position”.
+	tpStepPctRe = regexp.MustCompile(`(?i)(?:\(\d+\s+out of\s+\d+\)\s*[,:-]?\s*(\d+(?:\.\d+)?)\s*%|(\d+(?:\.\d+)?)\s*%\s+of\s+(?:the\s+)?(?:position|volume|amount))`)
+	// profitUSDLeadRe matches a USD profit right after “Profit:”, as
+	// “(4.54 $)”, “4.54 $” or “$4.54”, optionally followed by the native profit.
+	profitUSDLeadRe = regexp.MustCompile(`Profit:\s*(?:\(\s*([+-]?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)\s*\$\s*\)|([+-]?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)\s*\$|([+-]?)\$\s*(\d+(?:\.\d+)?(?:[eE][+-]?\d+)?))\s*,?\s*`)
+	// dollarPrefixRe and dollarSuffixRe match a price or size given in $,
+	// “Size: $25.09” or “Size: 25.09 $”, which is read as USD.
+	dollarPrefixRe = regexp.MustCompile(`(Price:|Size:)\s*\$\s*(\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)`)
+	dollarSuffixRe = regexp.MustCompile(`(Price:|Size:)\s*(\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)\s*\$`)
+	// leadingPrefixRe matches one "[MyBot]" or timestamp prefix added when
+	// messages are exported or forwarded.
+	leadingPrefixRe = regexp.MustCompile(`^(?:\[[^\]]*\]|\d{4}-\d{2}-\d{2}(?:[T\s]+\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?)?|\d{2}:\d{2}(?::\d{2})?)\s*(?:[-|:]\s*)?`)
 )
 
 // Strategy enumerates the deal direction to decide BUY/SELL.
@@ -67,11 +86,12 @@ const (
 type Status string
 
 const (
-	StatusUnknown   Status = ""
-	StatusActive    Status = "Active"
-	StatusFilled    Status = "Filled"
-	StatusCancelled Status = "Cancelled"
-	StatusFinished  Status = "Finished"
+	StatusUnknown    Status = ""
+	StatusActive     Status = "Active"
+	StatusFilled     Status = "Filled"
+	StatusCancelling Status = "Cancelling"
+	StatusCancelled  Status = "Cancelled"
+	StatusFinished   Status = "Finished"
 )
 
 // Context conveys deal-level metadata that messages omit.
@@ -79,6 +99,11 @@ type Context struct {
 	Strategy      Strategy
 	BaseCurrency  string
 	QuoteCurrency string
+	// KeepProfitPercentageSign disables the normalization of the parsed
+	// ProfitPercentage to the sign of the Profit. Some messages of short deals
+	// omit or invert it, by default a -4.53 USDT profit "(2.0% from total
+	// volume)" gives a ProfitPercentage of -2.0.
+	KeepProfitPercentageSign bool
 }
 
 // Event is the parsed form of a bot event message.
@@ -91,6 +116,7 @@ type Event struct {
 	QuoteCurrency    string
 	QuoteVolume      float64
 	Price            float64
+	PriceCurrency    string
 	IsMarket         bool
 	Size             float64
 	OrderPosition    int
@@ -99,52 +125,121 @@ type Event struct {
 	ProfitCurrency   string
 	ProfitUSD        float64
 	ProfitPercentage float64
-	Text             string
+	// RiskReduction is set for the absolute form "Risk reduction 1.1366 USDT",
+	// RiskReductionPercentage for the "(Risk reduction 5%)" form.
+	RiskReduction           float64
+	RiskReductionCurrency   string
+	RiskReductionPercentage float64
+	// RangeStart and RangeEnd are set when one message covers several
+	// orders, e.g. "Placing averaging orders (1-9)".
+	RangeStart int
+	RangeEnd   int
+	// TakeProfitStepPercentage is the share of the position closed by one
+	// step of a split take profit, e.g. 50 for "(1 out of 3) 50%".
+	TakeProfitStepPercentage float64
+	// StrategyUsed is the strategy the Side was inferred with: the Context's,
+	// unless the message names a buy or sell order.
+	StrategyUsed Strategy
+	// ReduceOnly is set for futures orders flagged "reduce-only", which can
+	// only close the position.
+	ReduceOnly bool
+	// Source tells bot events from smart trade events, see ParseSmartTrade.
+	Source EventSource
+	// MatchedRule identifies the message format the event was parsed from,
+	// e.g. "base_order_executed_v1", to track which formats are in use. It
+	// names the wording that matched, so "Averaging order" and "DCA order"
+	// messages get different rules. It is empty when the action or order
+	// type was not recognised.
+	MatchedRule string
+	// CreatedAt is the time of the bot event, set by ParseBotEventsJSON. Parse
+	// leaves it zero as messages carry no timestamp.
+	CreatedAt time.Time
+	// ApproxDuration is taken from the hashtag tail of a completed trade,
+	// e.g. "#profit about 23 hours".
+	ApproxDuration time.Duration
+	Text           string
 }
 
 // ErrEmptyMessage indicates the parser received nothing useful.
 var ErrEmptyMessage = errors.New("eventparser: empty message")
 
+// Trace reports how much of a message the parser was able to extract.
+type Trace struct {
+	// FieldsParsed counts how many of action, order type, side, price, size
+	// and profit were successfully extracted. Values taken from the Context
+	// (e.g. the coin) do not count.
+	FieldsParsed int
+}
+
 // Parse analyses a single bot event message.
 func Parse(message string, ctx Context) (Event, error) {
+	event, _, err := ParseWithTrace(message, ctx)
+	return event, err
+}
+
+// ParseWithTrace behaves like Parse but also returns a Trace that can be
+// used as a quality signal for the parsed Event.
+func ParseWithTrace(message string, ctx Context) (Event, Trace, error) {
+	var trace Trace
+
 	raw := strings.TrimSpace(message)
 	if raw == "" {
-		return Event{}, ErrEmptyMessage
+		return Event{}, trace, ErrEmptyMessage
 	}
 
 	normalized := normalize(raw)
 
 	event := Event{
-		Text: raw,
+		Text:           raw,
+		ApproxDuration: parseApproxDuration(raw),
 	}
 
-	firstClause := firstSentence(normalized)
+	// The reduce-only flag is dropped from the clause so it doesn't get in
+	// the way of the action and order type prefixes
+	event.ReduceOnly = reduceOnlyRe.MatchString(normalized)
+	firstClause := strings.TrimSpace(reduceOnlyRe.ReplaceAllString(firstSentence(normalized), ""))
 
-	action, subject := classifyAction(firstClause)
+	action, subject, actionRule := classifyAction(firstClause)
+	orderType, orderTypeRule := classifyOrderType(subject)
 	event.Action = action
-	event.OrderType = classifyOrderType(subject)
+	event.OrderType = orderType
 	event.Status = inferStatus(action)
 
-	if pos, total, ok := parseProgress(subject); ok {
+	if pos, total, ok := ParseProgress(subject); ok {
 		event.OrderPosition = pos
 		event.OrderSize = total
 		if event.OrderType == OrderTypeUnknown {
 			event.OrderType = OrderTypeSafety
+			orderTypeRule = "numbered_order"
 		}
 	}
 
-	if price, currency, isMarket := parsePrice(normalized); currency != "" || isMarket {
+	if start, end, ok := parseRange(subject); ok {
+		event.RangeStart = start
+		event.RangeEnd = end
+		if event.OrderType == OrderTypeUnknown {
+			event.OrderType = OrderTypeSafety
+			orderTypeRule = "order_range"
+		}
+	}
+
+	if price, currency, isMarket := ParsePrice(normalized); currency != "" || isMarket {
+		trace.FieldsParsed++
 		event.Price = price
+		event.PriceCurrency = currency
 		event.IsMarket = isMarket
 		if !isMarket && currency != "" && event.QuoteCurrency == "" {
 			event.QuoteCurrency = currency
 		}
 	}
 
-	if quoteVol, quoteCur, baseVol, baseCur := parseSize(normalized); quoteVol > 0 || baseVol > 0 {
+	if quoteVol, quoteCur, baseVol, baseCur := ParseSize(normalized, ctx.BaseCurrency); quoteVol > 0 || baseVol > 0 {
+		trace.FieldsParsed++
 		if quoteVol > 0 {
 			event.QuoteVolume = quoteVol
 		}
+		// The size's quote currency wins over the price currency, which
+		// stays available as PriceCurrency
 		if quoteCur != "" {
 			event.QuoteCurrency = quoteCur
 		}
@@ -157,10 +252,24 @@ func Parse(message string, ctx Context) (Event, error) {
 	}
 
 	if profit, cur, usd, pct := parseProfit(normalized); profit != 0 || cur != "" || usd != 0 || pct != 0 {
+		trace.FieldsParsed++
 		event.Profit = profit
 		event.ProfitCurrency = cur
 		event.ProfitUSD = usd
 		event.ProfitPercentage = pct
+		if !ctx.KeepProfitPercentageSign {
+			event.ProfitPercentage = matchSign(pct, profit)
+		}
+	}
+
+	if event.OrderType == OrderTypeTakeProfit {
+		event.TakeProfitStepPercentage = parseTakeProfitStep(normalized)
+	}
+
+	if amount, currency, pct := parseRiskReduction(normalized); amount != 0 || pct != 0 {
+		event.RiskReduction = amount
+		event.RiskReductionCurrency = currency
+		event.RiskReductionPercentage = pct
 	}
 
 	if event.Coin == "" {
@@ -170,21 +279,58 @@ func Parse(message string, ctx Context) (Event, error) {
 		event.QuoteCurrency = ctx.QuoteCurrency
 	}
 
-	event.Side = inferSide(event.OrderType, ctx)
+	event.StrategyUsed = ctx.Strategy
+	if strategy := strategyFromTokens(subject, event.OrderType); strategy != StrategyUnknown {
+		event.StrategyUsed = strategy
+	}
+	sideCtx := ctx
+	sideCtx.Strategy = event.StrategyUsed
+	event.Side = inferSide(event.OrderType, sideCtx)
+	event.MatchedRule = matchedRule(orderTypeRule, actionRule)
+
+	if event.Action != ActionUnknown {
+		trace.FieldsParsed++
+	}
+	if event.OrderType != OrderTypeUnknown {
+		trace.FieldsParsed++
+	}
+	if event.Side != SideUnknown {
+		trace.FieldsParsed++
+	}
 
-	return event, nil
+	return event, trace, nil
 }
 
 func parseProfit(input string) (amount float64, currency string, usd float64, pct float64) {
 	lower := strings.ToLower(input)
-	if match := profitRe.FindStringSubmatch(input); len(match) == 3 {
+
+	// With the USD profit first, the native profit (if any) follows it
+	native, usdLead := input, false
+	if match := profitUSDLeadRe.FindStringSubmatch(input); len(match) == 5 {
+		if val, err := strconv.ParseFloat(match[1]+match[2]+match[3]+match[4], 64); err == nil {
+			usd, usdLead = val, true
+			native = "Profit: " + input[strings.Index(input, match[0])+len(match[0]):]
+		}
+	}
+
+	if match := profitRe.FindStringSubmatch(native); len(match) == 3 {
 		if val, err := strconv.ParseFloat(match[1], 64); err == nil {
 			amount = val
 			currency = match[2]
 		}
+	} else if match := profitCurFirstRe.FindStringSubmatch(native); len(match) == 3 {
+		if val, err := strconv.ParseFloat(match[2], 64); err == nil {
+			amount = val
+			currency = match[1]
+		}
 	}
 
-	if match := profitUSDRe.FindStringSubmatch(input); len(match) == 2 {
+	if usdLead {
+		if currency == "" {
+			// Only the USD profit is given, the account is quoted in USD
+			amount, currency = usd, "USD"
+		}
+	} else if match := profitUSDRe.FindStringSubmatch(input); len(match) == 2 {
 		if val, err := strconv.ParseFloat(match[1], 64); err == nil {
 			usd = val
 		}
@@ -216,6 +362,70 @@ func parseProfit(input string) (amount float64, currency string, usd float64, pc
 	return amount, currency, usd, pct
 }
 
+// matchSign returns v with the sign of ref, or v unchanged when either is 0.
+func matchSign(v, ref float64) float64 {
+	if v == 0 || ref == 0 || (v < 0) == (ref < 0) {
+		return v
+	}
+	return -v
+}
+
+func parseRiskReduction(input string) (amount float64, currency string, pct float64) {
+	match := riskReductionRe.FindStringSubmatch(input)
+	if len(match) != 3 {
+		return 0, "", 0
+	}
+	val, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, "", 0
+	}
+	if match[2] == "%" {
+		return 0, "", val
+	}
+	return val, match[2], 0
+}
+
+func parseTakeProfitStep(input string) float64 {
+	match := tpStepPctRe.FindStringSubmatch(input)
+	if len(match) != 3 {
+		return 0
+	}
+	raw := match[1]
+	if raw == "" {
+		raw = match[2]
+	}
+	val, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0
+	}
+	return val
+}
+
+// parseApproxDuration reads the duration from the hashtag tail that normalize
+// strips, e.g. "#profit about 5 hours".
+func parseApproxDuration(input string) time.Duration {
+	idx := strings.Index(input, "#")
+	if idx == -1 {
+		return 0
+	}
+	match := durationRe.FindStringSubmatch(input[idx:])
+	if len(match) != 3 {
+		return 0
+	}
+	n := 1
+	if v, err := strconv.Atoi(match[1]); err == nil {
+		n = v
+	}
+	unit := time.Minute
+	switch strings.ToLower(match[2]) {
+	case "hour":
+		unit = time.Hour
+	case "day":
+		unit = 24 * time.Hour
+	}
+	return time.Duration(n) * unit
+}
+
 func normalize(input string) string {
 	noEmoji := strings.Map(func(r rune) rune {
 		if r > unicode.MaxASCII {
@@ -223,88 +433,169 @@ func normalize(input string) string {
 		}
 		return r
 	}, input)
-	beforeHash := noEmoji
+	beforeHash := stripPrefixes(strings.TrimSpace(noEmoji))
 	if idx := strings.Index(beforeHash, " #"); idx != -1 {
 		beforeHash = beforeHash[:idx]
 	}
 	return strings.Join(strings.Fields(beforeHash), " ")
 }
 
+// stripPrefixes removes leading bracketed and timestamp prefixes.
+func stripPrefixes(input string) string {
+	for {
+		loc := leadingPrefixRe.FindStringIndex(input)
+		if loc == nil || loc[1] == 0 {
+			return input
+		}
+		input = input[loc[1]:]
+	}
+}
+
+// firstSentence returns input up to the first ". " that ends a sentence. A
+// ". " right after a digit, "Size: 25.0. USDT", or after a single letter
+// abbreviation, "U.S. dollar", does not end it.
 func firstSentence(input string) string {
-	if idx := strings.Index(input, ". "); idx != -1 {
-		return strings.TrimSuffix(input[:idx], ".")
+	for start := 0; ; {
+		idx := strings.Index(input[start:], ". ")
+		if idx == -1 {
+			return strings.TrimSuffix(input, ".")
+		}
+		idx += start
+		if !afterDigit(input, idx) && !afterAbbreviation(input, idx) {
+			return strings.TrimSuffix(input[:idx], ".")
+		}
+		start = idx + 2
 	}
-	return strings.TrimSuffix(input, ".")
 }
 
-func classifyAction(clause string) (Action, string) {
+func afterDigit(input string, idx int) bool {
+	return idx > 0 && unicode.IsDigit(rune(input[idx-1]))
+}
+
+// afterAbbreviation reports whether the "." at idx ends a single letter that
+// follows another "." or starts a word, like the "S." of "U.S.".
+func afterAbbreviation(input string, idx int) bool {
+	if idx == 0 || !unicode.IsLetter(rune(input[idx-1])) {
+		return false
+	}
+	return idx == 1 || input[idx-2] == '.' || input[idx-2] == ' '
+}
+
+// classifyAction returns the action of the first clause, the clause's subject
+// for classifyOrderType and the rule naming the matched wording, e.g.
+// "executed", joined with the order type's rule by matchedRule.
+func classifyAction(clause string) (Action, string, string) {
 	lower := strings.ToLower(clause)
 
 	switch {
 	case strings.HasPrefix(lower, "placing "):
-		return ActionPlace, strings.TrimSpace(clause[len("Placing "):])
+		return ActionPlace, strings.TrimSpace(clause[len("Placing "):]), "placing"
 	case strings.HasPrefix(lower, "cancelling "):
-		return ActionCancel, strings.TrimSpace(clause[len("Cancelling "):])
-	case strings.HasPrefix(lower, "takeprofit trade cancelled"):
-		return ActionCancelled, strings.TrimSpace(clause)
+		return ActionCancel, strings.TrimSpace(clause[len("Cancelling "):]), "cancelling"
+	case strings.HasPrefix(lower, "takeprofit trade cancelled"),
+		// Checked before the stop loss summary, which shares the prefix
+		strings.HasPrefix(lower, "stoploss trade cancelled"),
+		strings.HasPrefix(lower, "stop loss trade cancelled"):
+		return ActionCancelled, strings.TrimSpace(clause), "trade_cancelled"
 	case strings.Contains(lower, "trade completed"):
-		return ActionCompleted, strings.TrimSpace(clause)
+		return ActionCompleted, strings.TrimSpace(clause), "completed"
 	case strings.HasPrefix(lower, "stop loss") || strings.HasPrefix(lower, "stoploss"):
-		return ActionCancelled, strings.TrimSpace(clause)
+		// The stop loss summary: the stop loss executed and the loss is realized
+		return ActionFinished, strings.TrimSpace(clause), "summary"
 	case strings.HasSuffix(lower, " finished"):
-		return ActionFinished, strings.TrimSpace(clause[:len(clause)-len(" finished")])
+		return ActionFinished, strings.TrimSpace(clause[:len(clause)-len(" finished")]), "finished"
 	case strings.HasSuffix(lower, " executed"):
-		return ActionExecute, strings.TrimSpace(clause[:len(clause)-len(" executed")])
+		return ActionExecute, strings.TrimSpace(clause[:len(clause)-len(" executed")]), "executed"
 	case strings.HasSuffix(lower, " cancelled"):
-		return ActionCancelled, strings.TrimSpace(clause[:len(clause)-len(" cancelled")])
+		return ActionCancelled, strings.TrimSpace(clause[:len(clause)-len(" cancelled")]), "cancelled"
 	default:
-		return ActionUnknown, strings.TrimSpace(clause)
+		return ActionUnknown, strings.TrimSpace(clause), ""
 	}
 }
 
-func classifyOrderType(subject string) OrderType {
+// classifyOrderType returns the order type named in subject and the rule
+// naming its wording, e.g. "averaging_order" or "dca_order" for a safety order.
+func classifyOrderType(subject string) (OrderType, string) {
 	lower := strings.ToLower(subject)
 	switch {
 	case strings.Contains(lower, "base order"):
-		return OrderTypeBase
-	case strings.Contains(lower, "averaging order"):
-		return OrderTypeSafety
+		return OrderTypeBase, "base_order"
+	// Manual safety goes first, "manual safety order" contains "safety order"
 	case strings.Contains(lower, "manual safety"):
-		return OrderTypeManualSafety
+		return OrderTypeManualSafety, "manual_safety_order"
+	case strings.Contains(lower, "averaging order"):
+		return OrderTypeSafety, "averaging_order"
+	case strings.Contains(lower, "dca order"):
+		return OrderTypeSafety, "dca_order"
+	case strings.Contains(lower, "safety order"):
+		return OrderTypeSafety, "safety_order"
 	case strings.Contains(lower, "takeprofit"):
-		return OrderTypeTakeProfit
-	case strings.Contains(lower, "stop loss") || strings.Contains(lower, "stoploss"):
-		return OrderTypeStopLoss
+		return OrderTypeTakeProfit, "take_profit"
+	case strings.Contains(lower, "stop loss"):
+		return OrderTypeStopLoss, "stop_loss"
+	case strings.Contains(lower, "stoploss"):
+		return OrderTypeStopLoss, "stoploss"
 	case strings.Contains(lower, "trade completed"):
-		return OrderTypeSummary
+		return OrderTypeSummary, "trade"
 	default:
-		return OrderTypeUnknown
+		return OrderTypeUnknown, ""
+	}
+}
+
+// ruleVersion is bumped when the wording of a known format changes.
+const ruleVersion = "v1"
+
+// matchedRule returns the Event.MatchedRule joining the rules of the matched
+// order type and action wordings, e.g. "dca_order_executed_v1". It is empty
+// when either wording was not recognised.
+func matchedRule(orderTypeRule, actionRule string) string {
+	if orderTypeRule == "" || actionRule == "" {
+		return ""
 	}
+	return orderTypeRule + "_" + actionRule + "_" + ruleVersion
 }
 
-func parseProgress(subject string) (position int, total int, ok bool) {
-	match := progressRe.FindStringSubmatch(subject)
+func parseRange(s string) (start, end int, ok bool) {
+	match := rangeRe.FindStringSubmatch(s)
+	if len(match) != 3 {
+		return 0, 0, false
+	}
+	start, err1 := strconv.Atoi(match[1])
+	end, err2 := strconv.Atoi(match[2])
+	if err1 != nil || err2 != nil || start < 1 || end < start {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// ParseProgress extracts the "(N out of M)" progress of a safety order, e.g.
+// "Placing averaging order (8 out of 9)" yields 8, 9, true.
+func ParseProgress(s string) (pos, total int, ok bool) {
+	match := progressRe.FindStringSubmatch(s)
 	if len(match) != 3 {
 		return 0, 0, false
 	}
 	pos, err1 := strconv.Atoi(match[1])
-	tot, err2 := strconv.Atoi(match[2])
+	total, err2 := strconv.Atoi(match[2])
 	if err1 != nil || err2 != nil {
 		return 0, 0, false
 	}
-	return pos, tot, true
+	return pos, total, true
 }
 
-func parsePrice(input string) (price float64, currency string, isMarket bool) {
-	match := priceRe.FindStringSubmatch(input)
+// ParsePrice extracts "Price: <value> <currency>" from a message. For
+// "Price: market" it returns isMarket true and no price or currency. A price
+// in $ ("Price: $0.25") is returned in USD. A negative price is ignored.
+func ParsePrice(input string) (price float64, currency string, isMarket bool) {
+	match := priceRe.FindStringSubmatch(dollarsToUSD(input))
 	if len(match) < 2 {
 		return 0, "", false
 	}
-	if match[1] == "market" {
+	if strings.EqualFold(match[1], "market") {
 		return 0, "", true
 	}
 	val, err := strconv.ParseFloat(match[1], 64)
-	if err != nil {
+	if err != nil || val < 0 {
 		return 0, "", false
 	}
 	if len(match) >= 3 {
@@ -313,36 +604,80 @@ func parsePrice(input string) (price float64, currency string, isMarket bool) {
 	return val, "", false
 }
 
-func parseSize(input string) (quoteVol float64, quoteCur string, baseVol float64, baseCur string) {
+// ParseSize extracts "Size: <quote> (<base>)". When there is no base
+// parenthetical and the size is stated in baseCurrency ("Size: 110.0 DOGE"),
+// it is returned as the base size instead. A size in $ is returned in USD.
+// Negative sizes are ignored.
+func ParseSize(input, baseCurrency string) (quoteVol float64, quoteCur string, baseVol float64, baseCur string) {
+	input = dollarsToUSD(input)
 	match := sizeRe.FindStringSubmatch(input)
 	if len(match) < 3 {
 		return 0, "", 0, ""
 	}
 	qVol, err := strconv.ParseFloat(match[1], 64)
-	if err == nil {
+	if err == nil && qVol >= 0 {
 		quoteVol = qVol
 	}
 	quoteCur = match[2]
-	sizeSegment := input[strings.Index(input, match[0]):]
-	if combos := baseSizeRe.FindAllStringSubmatch(sizeSegment, -1); len(combos) > 0 {
-		last := combos[len(combos)-1]
-		if len(last) >= 3 {
-			if bVol, err := strconv.ParseFloat(last[1], 64); err == nil {
-				baseVol = bVol
-			}
-			baseCur = last[2]
+	sizeSegment := untilStrayParen(input[strings.Index(input, match[0]):])
+	// The base size is the last "(110.0 DOGE)", labeled parentheticals like
+	// "(Risk reduction 1.1366 USDT)" are skipped
+	var base []string
+	for _, combo := range baseSizeRe.FindAllStringSubmatch(sizeSegment, -1) {
+		if combo[1] == "" {
+			base = combo
+		}
+	}
+	if base != nil {
+		if bVol, err := strconv.ParseFloat(base[2], 64); err == nil && bVol >= 0 {
+			baseVol = bVol
 		}
+		baseCur = base[3]
+	} else if baseCurrency != "" && strings.EqualFold(quoteCur, baseCurrency) {
+		return 0, "", quoteVol, quoteCur
 	}
 	return quoteVol, quoteCur, baseVol, baseCur
 }
 
+// untilStrayParen cuts s at the first ")" that closes no "(" in s, so the
+// base size isn't taken from a parenthetical past a stray paren like the
+// summary's "total volume)).".
+func untilStrayParen(s string) string {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return s[:i]
+			}
+			depth--
+		}
+	}
+	return s
+}
+
+// dollarsToUSD rewrites a price or size given in $ to USD, e.g. “Size: $25.09”
+// to “Size: 25.09 USD”. Other dollar signs, like the profit's “(4.54 $)”, are
+// left alone.
+func dollarsToUSD(input string) string {
+	if !strings.Contains(input, "$") {
+		return input
+	}
+	input = dollarPrefixRe.ReplaceAllString(input, "${1} ${2} USD")
+	return dollarSuffixRe.ReplaceAllString(input, "${1} ${2} USD")
+}
+
 func inferStatus(action Action) Status {
 	switch action {
 	case ActionPlace:
 		return StatusActive
 	case ActionExecute:
 		return StatusFilled
-	case ActionCancel, ActionCancelled:
+	case ActionCancel:
+		return StatusCancelling
+	case ActionCancelled:
 		return StatusCancelled
 	case ActionFinished, ActionCompleted:
 		return StatusFinished
@@ -351,11 +686,45 @@ func inferStatus(action Action) Status {
 	}
 }
 
+// strategyFromTokens infers the strategy from an explicit "buy order" or
+// "sell order" in the subject: buying opens a long position and closes a
+// short one.
+func strategyFromTokens(subject string, orderType OrderType) Strategy {
+	lower := strings.ToLower(subject)
+	buy := strings.Contains(lower, "buy order")
+	sell := strings.Contains(lower, "sell order")
+	if buy == sell {
+		return StrategyUnknown
+	}
+	switch orderType {
+	case OrderTypeBase, OrderTypeSafety, OrderTypeManualSafety:
+		if buy {
+			return StrategyLong
+		}
+		return StrategyShort
+	case OrderTypeTakeProfit, OrderTypeStopLoss:
+		if buy {
+			return StrategyShort
+		}
+		return StrategyLong
+	default:
+		return StrategyUnknown
+	}
+}
+
 func inferSide(orderType OrderType, ctx Context) Side {
+	return SideFor(orderType, ctx.Strategy)
+}
+
+// SideFor returns the side of an order of the given type in a deal with the
+// given strategy: long deals buy to open and sell to close, short deals the
+// other way around. Without a strategy the deal is taken to be long. Unknown
+// and summary events have no side.
+func SideFor(orderType OrderType, strategy Strategy) Side {
 	if orderType == OrderTypeUnknown || orderType == OrderTypeSummary {
 		return SideUnknown
 	}
-	switch ctx.Strategy {
+	switch strategy {
 	case StrategyLong:
 		if orderType == OrderTypeTakeProfit || orderType == OrderTypeStopLoss {
 			return SideSell
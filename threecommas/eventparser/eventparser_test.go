@@ -3,6 +3,7 @@ package eventparser
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -36,6 +37,150 @@ func TestParse(t *testing.T) {
 				Price:         0,
 				IsMarket:      true,
 				Size:          110.0,
+				MatchedRule:   "averaging_order_placing_v1",
+			},
+		},
+		{
+			name:    "placing_averaging_capitalized_market",
+			message: "Placing averaging order (9 out of 9). Price: Market Size: 25.0008 USDT (110.0 DOGE)",
+			want: Event{
+				Action:        ActionPlace,
+				OrderType:     OrderTypeSafety,
+				Side:          SideBuy,
+				Status:        StatusActive,
+				OrderPosition: 9,
+				OrderSize:     9,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   25.0008,
+				IsMarket:      true,
+				Size:          110.0,
+				MatchedRule:   "averaging_order_placing_v1",
+			},
+		},
+		{
+			name:    "placing_dca_order",
+			message: "Placing DCA order (2 out of 5). Price: 0.22815 USDT Size: 25.0965 USDT (110.0 DOGE)",
+			want: Event{
+				Action:        ActionPlace,
+				OrderType:     OrderTypeSafety,
+				Side:          SideBuy,
+				Status:        StatusActive,
+				OrderPosition: 2,
+				OrderSize:     5,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   25.0965,
+				Price:         0.22815,
+				PriceCurrency: "USDT",
+				Size:          110.0,
+				MatchedRule:   "dca_order_placing_v1",
+			},
+		},
+		{
+			name:    "executed_dca_order",
+			message: "dca order (2 out of 5) executed. Price: market Size: 25.0269019 USDT (110.0 DOGE)",
+			want: Event{
+				Action:        ActionExecute,
+				OrderType:     OrderTypeSafety,
+				Side:          SideBuy,
+				Status:        StatusFilled,
+				OrderPosition: 2,
+				OrderSize:     5,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   25.0269019,
+				IsMarket:      true,
+				Size:          110.0,
+				MatchedRule:   "dca_order_executed_v1",
+			},
+		},
+		{
+			name:    "placing_dca_order_without_progress",
+			message: "Placing DCA order. Price: 0.22815 USDT Size: 25.0965 USDT (110.0 DOGE)",
+			want: Event{
+				Action:        ActionPlace,
+				OrderType:     OrderTypeSafety,
+				Side:          SideBuy,
+				Status:        StatusActive,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   25.0965,
+				Price:         0.22815,
+				PriceCurrency: "USDT",
+				Size:          110.0,
+				MatchedRule:   "dca_order_placing_v1",
+			},
+		},
+		{
+			name:    "placing_safety_order",
+			message: "Placing safety order (2 out of 5). Price: 0.22815 USDT Size: 25.0965 USDT (110.0 DOGE)",
+			want: Event{
+				Action:        ActionPlace,
+				OrderType:     OrderTypeSafety,
+				Side:          SideBuy,
+				Status:        StatusActive,
+				OrderPosition: 2,
+				OrderSize:     5,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   25.0965,
+				Price:         0.22815,
+				PriceCurrency: "USDT",
+				Size:          110.0,
+				MatchedRule:   "safety_order_placing_v1",
+			},
+		},
+		{
+			name:    "executed_safety_order",
+			message: "Safety order (2 out of 5) executed. Price: market Size: 25.0269019 USDT (110.0 DOGE)",
+			want: Event{
+				Action:        ActionExecute,
+				OrderType:     OrderTypeSafety,
+				Side:          SideBuy,
+				Status:        StatusFilled,
+				OrderPosition: 2,
+				OrderSize:     5,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   25.0269019,
+				IsMarket:      true,
+				Size:          110.0,
+				MatchedRule:   "safety_order_executed_v1",
+			},
+		},
+		{
+			name:    "placing_manual_safety_order",
+			message: "Placing manual safety order. Price: 0.22815 USDT Size: 25.0965 USDT (110.0 DOGE)",
+			want: Event{
+				Action:        ActionPlace,
+				OrderType:     OrderTypeManualSafety,
+				Side:          SideBuy,
+				Status:        StatusActive,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   25.0965,
+				Price:         0.22815,
+				PriceCurrency: "USDT",
+				Size:          110.0,
+				MatchedRule:   "manual_safety_order_placing_v1",
+			},
+		},
+		{
+			name:    "executed_base_order_in_dollars",
+			message: "Base order executed. Price: $0.22758736. Size: $25.03461 (110.0 DOGE)",
+			want: Event{
+				Action:        ActionExecute,
+				OrderType:     OrderTypeBase,
+				Side:          SideBuy,
+				Status:        StatusFilled,
+				Coin:          "DOGE",
+				QuoteCurrency: "USD",
+				QuoteVolume:   25.03461,
+				Price:         0.22758736,
+				PriceCurrency: "USD",
+				Size:          110.0,
+				MatchedRule:   "base_order_executed_v1",
 			},
 		},
 		{
@@ -54,6 +199,27 @@ func TestParse(t *testing.T) {
 				Price:         0,
 				IsMarket:      true,
 				Size:          110.0,
+				MatchedRule:   "averaging_order_executed_v1",
+			},
+		},
+		{
+			// Some bot versions omit the progress, the position stays unknown
+			name:    "executed_averaging_without_progress",
+			message: "Averaging order executed. Price: 0.22815 USDT Size: 25.0965 USDT (110.0 DOGE)",
+			want: Event{
+				Action:        ActionExecute,
+				OrderType:     OrderTypeSafety,
+				Side:          SideBuy,
+				Status:        StatusFilled,
+				OrderPosition: 0,
+				OrderSize:     0,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   25.0965,
+				Price:         0.22815,
+				PriceCurrency: "USDT",
+				Size:          110.0,
+				MatchedRule:   "averaging_order_executed_v1",
 			},
 		},
 		{
@@ -63,13 +229,15 @@ func TestParse(t *testing.T) {
 				Action:        ActionCancel,
 				OrderType:     OrderTypeTakeProfit,
 				Side:          SideSell,
-				Status:        StatusCancelled,
+				Status:        StatusCancelling,
 				Coin:          "DOGE",
 				QuoteCurrency: "USDT",
 				QuoteVolume:   230.93496,
 				Price:         0.23469,
+				PriceCurrency: "USDT",
 				IsMarket:      false,
 				Size:          984.0,
+				MatchedRule:   "take_profit_cancelling_v1",
 			},
 		},
 		{
@@ -79,15 +247,17 @@ func TestParse(t *testing.T) {
 				Action:        ActionCancel,
 				OrderType:     OrderTypeSafety,
 				Side:          SideBuy,
-				Status:        StatusCancelled,
+				Status:        StatusCancelling,
 				OrderPosition: 3,
 				OrderSize:     9,
 				Coin:          "DOGE",
 				QuoteCurrency: "USDT",
 				QuoteVolume:   25.0965,
 				Price:         0.22815,
+				PriceCurrency: "USDT",
 				IsMarket:      false,
 				Size:          110.0,
+				MatchedRule:   "numbered_order_cancelling_v1",
 			},
 		},
 		{
@@ -102,8 +272,61 @@ func TestParse(t *testing.T) {
 				QuoteCurrency: "USDT",
 				QuoteVolume:   230.93496,
 				Price:         0.23469,
+				PriceCurrency: "USDT",
 				IsMarket:      false,
 				Size:          984.0,
+				MatchedRule:   "take_profit_trade_cancelled_v1",
+			},
+		},
+		{
+			name:    "cancelling_stoploss",
+			message: "Cancelling StopLoss trade. Price: 0.21012 USDT Size: 206.75808 USDT (984.0 DOGE)",
+			want: Event{
+				Action:        ActionCancel,
+				OrderType:     OrderTypeStopLoss,
+				Side:          SideSell,
+				Status:        StatusCancelling,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   206.75808,
+				Price:         0.21012,
+				PriceCurrency: "USDT",
+				Size:          984.0,
+				MatchedRule:   "stoploss_cancelling_v1",
+			},
+		},
+		{
+			name:    "stoploss_cancelled",
+			message: "StopLoss trade cancelled. Price: 0.21012 USDT Size: 206.75808 USDT (984.0 DOGE)",
+			want: Event{
+				Action:        ActionCancelled,
+				OrderType:     OrderTypeStopLoss,
+				Side:          SideSell,
+				Status:        StatusCancelled,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   206.75808,
+				Price:         0.21012,
+				PriceCurrency: "USDT",
+				Size:          984.0,
+				MatchedRule:   "stoploss_trade_cancelled_v1",
+			},
+		},
+		{
+			name:    "stop_loss_cancelled_spaced",
+			message: "Stop Loss trade cancelled. Price: 0.21012 USDT Size: 206.75808 USDT (984.0 DOGE)",
+			want: Event{
+				Action:        ActionCancelled,
+				OrderType:     OrderTypeStopLoss,
+				Side:          SideSell,
+				Status:        StatusCancelled,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   206.75808,
+				Price:         0.21012,
+				PriceCurrency: "USDT",
+				Size:          984.0,
+				MatchedRule:   "stop_loss_trade_cancelled_v1",
 			},
 		},
 		{
@@ -118,24 +341,47 @@ func TestParse(t *testing.T) {
 				QuoteCurrency: "USDT",
 				QuoteVolume:   256.4883,
 				Price:         0.23445,
+				PriceCurrency: "USDT",
 				IsMarket:      false,
 				Size:          1094.0,
+				MatchedRule:   "take_profit_placing_v1",
 			},
 		},
 		{
 			name:    "placing_base_order_risk_reduction",
 			message: "Placing base order. Price: market Size: 39.38256 USDT (Risk reduction 5.62584 USDT) (168.0 DOGE)",
 			want: Event{
-				Action:        ActionPlace,
+				Action:                ActionPlace,
+				OrderType:             OrderTypeBase,
+				Side:                  SideBuy,
+				Status:                StatusActive,
+				Coin:                  "DOGE",
+				QuoteCurrency:         "USDT",
+				QuoteVolume:           39.38256,
+				Price:                 0,
+				IsMarket:              true,
+				Size:                  168.0,
+				RiskReduction:         5.62584,
+				RiskReductionCurrency: "USDT",
+				MatchedRule:           "base_order_placing_v1",
+			},
+		},
+		{
+			name:    "executed_base_order_double_space_period",
+			message: "Base order executed.  Price: 0.22758736 USDT.  Size: 25.03461 USDT (110.0 DOGE)",
+			want: Event{
+				Action:        ActionExecute,
 				OrderType:     OrderTypeBase,
 				Side:          SideBuy,
-				Status:        StatusActive,
+				Status:        StatusFilled,
 				Coin:          "DOGE",
 				QuoteCurrency: "USDT",
-				QuoteVolume:   39.38256,
-				Price:         0,
-				IsMarket:      true,
-				Size:          168.0,
+				QuoteVolume:   25.03461,
+				Price:         0.22758736,
+				PriceCurrency: "USDT",
+				IsMarket:      false,
+				Size:          110.0,
+				MatchedRule:   "base_order_executed_v1",
 			},
 		},
 		{
@@ -152,22 +398,24 @@ func TestParse(t *testing.T) {
 				Price:         0,
 				IsMarket:      true,
 				Size:          1698.0,
+				MatchedRule:   "stoploss_placing_v1",
 			},
 		},
 		{
 			name:    "stoploss_summary",
 			message: "Stop loss 📛  -17.51435838 USDT (-17.51 $) (-4.43% from total volume) #stoploss",
 			want: Event{
-				Action:           ActionCancelled,
+				Action:           ActionFinished,
 				OrderType:        OrderTypeStopLoss,
 				Side:             SideSell,
-				Status:           StatusCancelled,
+				Status:           StatusFinished,
 				Coin:             "DOGE",
 				QuoteCurrency:    "USDT",
 				Profit:           -17.51435838,
 				ProfitCurrency:   "USDT",
 				ProfitUSD:        -17.51,
 				ProfitPercentage: -4.43,
+				MatchedRule:      "stop_loss_summary_v1",
 			},
 		},
 		{
@@ -182,7 +430,9 @@ func TestParse(t *testing.T) {
 				QuoteCurrency: "USDT",
 				QuoteVolume:   230.95976904,
 				Price:         0.23072904,
+				PriceCurrency: "USDT",
 				Size:          1001.0,
+				MatchedRule:   "take_profit_finished_v1",
 			},
 		},
 		{
@@ -199,6 +449,90 @@ func TestParse(t *testing.T) {
 				ProfitCurrency:   "USDT",
 				ProfitUSD:        4.54,
 				ProfitPercentage: 2.0,
+				ApproxDuration:   5 * time.Hour,
+				MatchedRule:      "trade_completed_v1",
+			},
+		},
+		{
+			name:    "trade_completed_without_pair_prefix",
+			message: "Trade completed. Profit:  +4.53711258 USDT (4.54 $) (2.0% from total volume) 💰💰💰). #profit about 5 hours",
+			want: Event{
+				Action:           ActionCompleted,
+				OrderType:        OrderTypeSummary,
+				Side:             SideUnknown,
+				Status:           StatusFinished,
+				Coin:             "DOGE",
+				QuoteCurrency:    "USDT",
+				Profit:           4.53711258,
+				ProfitCurrency:   "USDT",
+				ProfitUSD:        4.54,
+				ProfitPercentage: 2.0,
+				ApproxDuration:   5 * time.Hour,
+				MatchedRule:      "trade_completed_v1",
+			},
+		},
+		{
+			name:    "trade_completed_currency_first",
+			message: "(USDT_DOGE): Trade completed. Profit: USDT +4.53711258 (4.54 $) (2.0% from total volume) 💰💰💰). #profit about 5 hours",
+			want: Event{
+				Action:           ActionCompleted,
+				OrderType:        OrderTypeSummary,
+				Side:             SideUnknown,
+				Status:           StatusFinished,
+				Coin:             "DOGE",
+				QuoteCurrency:    "USDT",
+				Profit:           4.53711258,
+				ProfitCurrency:   "USDT",
+				ProfitUSD:        4.54,
+				ProfitPercentage: 2.0,
+				ApproxDuration:   5 * time.Hour,
+				MatchedRule:      "trade_completed_v1",
+			},
+		},
+		{
+			name:    "trade_completed_currency_first_loss",
+			message: "(USDT_DOGE): Trade completed. Profit: USDT -1.25",
+			want: Event{
+				Action:         ActionCompleted,
+				OrderType:      OrderTypeSummary,
+				Side:           SideUnknown,
+				Status:         StatusFinished,
+				Coin:           "DOGE",
+				QuoteCurrency:  "USDT",
+				Profit:         -1.25,
+				ProfitCurrency: "USDT",
+				MatchedRule:    "trade_completed_v1",
+			},
+		},
+		{
+			name:    "placing_base_order_base_only_size",
+			message: "Placing base order. Price: 0.25 USDT Size: 110.0 DOGE",
+			want: Event{
+				Action:        ActionPlace,
+				OrderType:     OrderTypeBase,
+				Side:          SideBuy,
+				Status:        StatusActive,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				Price:         0.25,
+				PriceCurrency: "USDT",
+				Size:          110.0,
+				MatchedRule:   "base_order_placing_v1",
+			},
+		},
+		{
+			name:    "executed_tp_base_only_size_lowercase",
+			message: "TakeProfit trade executed. Price: market Size: 984.0 doge",
+			want: Event{
+				Action:        ActionExecute,
+				OrderType:     OrderTypeTakeProfit,
+				Side:          SideSell,
+				Status:        StatusFilled,
+				Coin:          "doge",
+				QuoteCurrency: "USDT",
+				IsMarket:      true,
+				Size:          984.0,
+				MatchedRule:   "take_profit_executed_v1",
 			},
 		},
 	}
@@ -227,7 +561,7 @@ func TestParse(t *testing.T) {
 			diff := cmp.Diff(
 				tt.want,
 				got,
-				cmpopts.IgnoreFields(Event{}, "Text"),
+				cmpopts.IgnoreFields(Event{}, "Text", "StrategyUsed"),
 				cmpopts.EquateApprox(0, 1e-6),
 			)
 			if diff != "" {
@@ -236,3 +570,1248 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePriceTrailingPeriod(t *testing.T) {
+	// The period after the price currency must not become part of the currency.
+	price, currency, isMarket := ParsePrice("Base order executed. Price: 0.22758736 USDT. Size: 25.03461 USDT (110.0 DOGE)")
+	if price != 0.22758736 || currency != "USDT" || isMarket {
+		t.Fatalf("ParsePrice() = %v, %q, %v", price, currency, isMarket)
+	}
+}
+
+func TestParseShortStrategy(t *testing.T) {
+	ctx := Context{
+		Strategy:      StrategyShort,
+		BaseCurrency:  "DOGE",
+		QuoteCurrency: "USDT",
+	}
+
+	// A short deal opens by selling and closes (take profit, stop loss) by buying
+	tests := []struct {
+		name     string
+		message  string
+		wantType OrderType
+		wantSide Side
+	}{
+		{
+			name:     "stoploss_summary",
+			message:  "Stop loss 📛  -17.51435838 USDT (-17.51 $) (-4.43% from total volume) #stoploss",
+			wantType: OrderTypeStopLoss,
+			wantSide: SideBuy,
+		},
+		{
+			name:     "placing_base_order",
+			message:  "Placing base order. Price: market Size: 24.9 USDT (110.0 DOGE)",
+			wantType: OrderTypeBase,
+			wantSide: SideSell,
+		},
+		{
+			name:     "executed_averaging",
+			message:  "Averaging order (2 out of 9) executed. Price: 0.2301 USDT Size: 25.311 USDT (110.0 DOGE)",
+			wantType: OrderTypeSafety,
+			wantSide: SideSell,
+		},
+		{
+			name:     "placing_tp",
+			message:  "Placing TakeProfit trade. Price: 0.22 USDT Size: 24.2 USDT (110.0 DOGE), the price should drop for 3.0% to close the trade",
+			wantType: OrderTypeTakeProfit,
+			wantSide: SideBuy,
+		},
+		{
+			name:     "trade_completed_summary",
+			message:  "(USDT_DOGE): Trade completed. Profit:  +4.53711258 USDT (4.54 $) (2.0% from total volume)",
+			wantType: OrderTypeSummary,
+			wantSide: SideUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.OrderType != tt.wantType || got.Side != tt.wantSide {
+				t.Fatalf("Parse() = %q %q, want %q %q", got.OrderType, got.Side, tt.wantType, tt.wantSide)
+			}
+		})
+	}
+}
+
+func TestSideFor(t *testing.T) {
+	tests := []struct {
+		orderType OrderType
+		long      Side
+		short     Side
+		unknown   Side
+	}{
+		{OrderTypeBase, SideBuy, SideSell, SideBuy},
+		{OrderTypeSafety, SideBuy, SideSell, SideBuy},
+		{OrderTypeManualSafety, SideBuy, SideSell, SideBuy},
+		{OrderTypeTakeProfit, SideSell, SideBuy, SideSell},
+		{OrderTypeStopLoss, SideSell, SideBuy, SideSell},
+		{OrderTypeSummary, SideUnknown, SideUnknown, SideUnknown},
+		{OrderTypeUnknown, SideUnknown, SideUnknown, SideUnknown},
+	}
+
+	for _, tt := range tests {
+		for strategy, want := range map[Strategy]Side{
+			StrategyLong:    tt.long,
+			StrategyShort:   tt.short,
+			StrategyUnknown: tt.unknown,
+		} {
+			if got := SideFor(tt.orderType, strategy); got != want {
+				t.Fatalf("SideFor(%q, %q) = %q, want %q", tt.orderType, strategy, got, want)
+			}
+		}
+	}
+}
+
+func TestParseStrategyUsed(t *testing.T) {
+	tests := []struct {
+		name         string
+		strategy     Strategy
+		message      string
+		wantStrategy Strategy
+		wantSide     Side
+	}{
+		{
+			name:         "long_from_context",
+			strategy:     StrategyLong,
+			message:      "Placing base order. Price: market Size: 24.9 USDT (110.0 DOGE)",
+			wantStrategy: StrategyLong,
+			wantSide:     SideBuy,
+		},
+		{
+			name:         "short_from_context",
+			strategy:     StrategyShort,
+			message:      "Placing base order. Price: market Size: 24.9 USDT (110.0 DOGE)",
+			wantStrategy: StrategyShort,
+			wantSide:     SideSell,
+		},
+		{
+			name:         "unknown_from_context",
+			message:      "Placing TakeProfit trade. Price: 0.26 USDT Size: 28.6 USDT (110.0 DOGE)",
+			wantStrategy: StrategyUnknown,
+			wantSide:     SideSell,
+		},
+		{
+			name:         "sell_order_overrides_long",
+			strategy:     StrategyLong,
+			message:      "Cancelling sell order (3 out of 9). Price: 0.22815 USDT Size: 25.0965 USDT (110.0 DOGE)",
+			wantStrategy: StrategyShort,
+			wantSide:     SideSell,
+		},
+		{
+			name:         "buy_order_overrides_short",
+			strategy:     StrategyShort,
+			message:      "Cancelling buy order (3 out of 9). Price: 0.22815 USDT Size: 25.0965 USDT (110.0 DOGE)",
+			wantStrategy: StrategyLong,
+			wantSide:     SideBuy,
+		},
+		{
+			name:         "buy_order_overrides_unknown",
+			message:      "Cancelling buy orders (3 - 9)",
+			wantStrategy: StrategyLong,
+			wantSide:     SideBuy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := Context{Strategy: tt.strategy, BaseCurrency: "DOGE", QuoteCurrency: "USDT"}
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.StrategyUsed != tt.wantStrategy || got.Side != tt.wantSide {
+				t.Fatalf("Parse() = %q %q, want %q %q", got.StrategyUsed, got.Side, tt.wantStrategy, tt.wantSide)
+			}
+		})
+	}
+}
+
+func TestParseMatchedRule(t *testing.T) {
+	ctx := Context{Strategy: StrategyLong, BaseCurrency: "DOGE", QuoteCurrency: "USDT"}
+
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Base order executed. Price: $0.22758736. Size: $25.03461 (110.0 DOGE)", "base_order_executed_v1"},
+		{"Placing averaging order (9 out of 9). Price: market Size: 25.0008 USDT (110.0 DOGE)", "averaging_order_placing_v1"},
+		{"Placing DCA order (2 out of 9). Price: 0.22 USDT Size: 25.0 USDT (113.0 DOGE)", "dca_order_placing_v1"},
+		{"Safety order (2 out of 9) executed. Price: 0.22 USDT Size: 25.0 USDT (113.0 DOGE)", "safety_order_executed_v1"},
+		{"Placing order (2 out of 9). Price: 0.22 USDT Size: 25.0 USDT (113.0 DOGE)", "numbered_order_placing_v1"},
+		{"Placing manual safety order. Price: 0.22815 USDT Size: 25.0965 USDT (110.0 DOGE)", "manual_safety_order_placing_v1"},
+		{"Cancelling TakeProfit trade. Price: 0.23469 USDT Size: 230.93496 USDT (984.0 DOGE)", "take_profit_cancelling_v1"},
+		{"TakeProfit trade cancelled. Price: 0.23469 USDT Size: 230.93496 USDT (984.0 DOGE)", "take_profit_trade_cancelled_v1"},
+		{"StopLoss trade cancelled. Price: 0.21012 USDT Size: 206.75808 USDT (984.0 DOGE)", "stoploss_trade_cancelled_v1"},
+		{"Stop loss trade cancelled. Price: 0.21012 USDT Size: 206.75808 USDT (984.0 DOGE)", "stop_loss_trade_cancelled_v1"},
+		{"Stop loss 📛  -17.51435838 USDT (-17.51 $) (-4.43% from total volume) #stoploss", "stop_loss_summary_v1"},
+		{"(USDT_DOGE): Trade completed. Profit:  +4.53711258 USDT (4.54 $) (2.0% from total volume) 💰💰💰). #profit about 5 hours", "trade_completed_v1"},
+		{"Something we have never seen before", ""},
+		{"Placing something new. Price: 0.25 USDT", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.message, ctx)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.message, err)
+		}
+		if got.MatchedRule != tt.want {
+			t.Fatalf("Parse(%q) MatchedRule = %q, want %q", tt.message, got.MatchedRule, tt.want)
+		}
+	}
+}
+
+func TestParseReduceOnly(t *testing.T) {
+	ctx := Context{Strategy: StrategyShort, BaseCurrency: "DOGE", QuoteCurrency: "USDT"}
+
+	tests := []struct {
+		name       string
+		message    string
+		wantAction Action
+		wantType   OrderType
+		wantSide   Side
+		wantReduce bool
+	}{
+		{
+			name:       "reduce_only_take_profit",
+			message:    "Placing reduce-only TakeProfit trade. Price: 0.21 USDT Size: 23.1 USDT (110.0 DOGE)",
+			wantAction: ActionPlace,
+			wantType:   OrderTypeTakeProfit,
+			wantSide:   SideBuy,
+			wantReduce: true,
+		},
+		{
+			name:       "reduceonly_stop_loss",
+			message:    "StopLoss (ReduceOnly) trade cancelled. Price: 0.25 USDT Size: 27.5 USDT (110.0 DOGE)",
+			wantAction: ActionCancelled,
+			wantType:   OrderTypeStopLoss,
+			wantSide:   SideBuy,
+			wantReduce: true,
+		},
+		{
+			name:       "not_reduce_only",
+			message:    "Placing TakeProfit trade. Price: 0.21 USDT Size: 23.1 USDT (110.0 DOGE)",
+			wantAction: ActionPlace,
+			wantType:   OrderTypeTakeProfit,
+			wantSide:   SideBuy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.Action != tt.wantAction || got.OrderType != tt.wantType || got.Side != tt.wantSide {
+				t.Fatalf("Parse() = %v %v %v, want %v %v %v", got.Action, got.OrderType, got.Side, tt.wantAction, tt.wantType, tt.wantSide)
+			}
+			if got.ReduceOnly != tt.wantReduce {
+				t.Fatalf("Parse() ReduceOnly = %v, want %v", got.ReduceOnly, tt.wantReduce)
+			}
+			if got.Size != 110 {
+				t.Fatalf("Parse() Size = %v, want 110", got.Size)
+			}
+		})
+	}
+}
+
+func TestParseStripsPrefixes(t *testing.T) {
+	ctx := Context{
+		Strategy:      StrategyLong,
+		BaseCurrency:  "DOGE",
+		QuoteCurrency: "USDT",
+	}
+	const plain = "Placing base order. Price: market Size: 24.9 USDT (110.0 DOGE)"
+
+	want, err := Parse(plain, ctx)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{name: "bracketed_bot_name", message: "[MyBot] " + plain},
+		{name: "bracketed_with_hash", message: "[DOGE bot #3]   " + plain},
+		{name: "timestamp", message: "2025-08-04 17:07:20 " + plain},
+		{name: "rfc3339_dash", message: "2025-08-04T17:07:20.171Z - " + plain},
+		{name: "time_colon", message: "17:07: " + plain},
+		{name: "bracketed_timestamp_and_bot", message: "[2025-08-04 17:07:20] [MyBot] " + plain},
+		{name: "timestamp_then_emoji_bot", message: "2025-08-04 17:07 🤖 [MyBot] " + plain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.Text != tt.message {
+				t.Fatalf("Text = %q, want the original message %q", got.Text, tt.message)
+			}
+			if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Event{}, "Text", "StrategyUsed")); diff != "" {
+				t.Fatalf("Parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseRiskReduction(t *testing.T) {
+	ctx := Context{
+		Strategy:      StrategyLong,
+		BaseCurrency:  "DOGE",
+		QuoteCurrency: "USDT",
+	}
+
+	tests := []struct {
+		name         string
+		message      string
+		wantAmount   float64
+		wantCurrency string
+		wantPct      float64
+		wantSize     float64
+	}{
+		{
+			name:         "absolute",
+			message:      "Placing base order. Price: market Size: 39.38256 USDT (Risk reduction 1.1366 USDT) (110.0 DOGE)",
+			wantAmount:   1.1366,
+			wantCurrency: "USDT",
+			wantSize:     110,
+		},
+		{
+			name:     "percentage",
+			message:  "Placing base order. Price: market Size: 39.38256 USDT (Risk reduction 5%) (110.0 DOGE)",
+			wantPct:  5,
+			wantSize: 110,
+		},
+		{
+			name:     "percentage_decimal",
+			message:  "Placing averaging order (4 out of 9). Price: 0.2201 USDT Size: 24.211 USDT (Risk reduction 2.5 %) (110.0 DOGE)",
+			wantPct:  2.5,
+			wantSize: 110,
+		},
+		{
+			name:     "absent",
+			message:  "Averaging order (3 out of 9) executed. Price: 0.22815 USDT Size: 25.0965 USDT (110.0 DOGE)",
+			wantSize: 110,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.RiskReduction != tt.wantAmount || got.RiskReductionCurrency != tt.wantCurrency {
+				t.Fatalf("RiskReduction = %v %q, want %v %q", got.RiskReduction, got.RiskReductionCurrency, tt.wantAmount, tt.wantCurrency)
+			}
+			if got.RiskReductionPercentage != tt.wantPct {
+				t.Fatalf("RiskReductionPercentage = %v, want %v", got.RiskReductionPercentage, tt.wantPct)
+			}
+			// The percentage must not be mistaken for a profit or size
+			if got.ProfitPercentage != 0 || got.Size != tt.wantSize {
+				t.Fatalf("ProfitPercentage = %v, Size = %v", got.ProfitPercentage, got.Size)
+			}
+		})
+	}
+}
+
+func TestParseTakeProfitStep(t *testing.T) {
+	ctx := Context{
+		Strategy:      StrategyLong,
+		BaseCurrency:  "DOGE",
+		QuoteCurrency: "USDT",
+	}
+
+	tests := []struct {
+		name         string
+		message      string
+		wantPct      float64
+		wantPosition int
+		wantSize     int
+	}{
+		{
+			name:         "of_the_position",
+			message:      "Placing TakeProfit trade (1 out of 3). Price: 0.23445 USDT Size: 128.24415 USDT (547.0 DOGE), 50% of the position, the price should rise for 3.16% to close the trade",
+			wantPct:      50,
+			wantPosition: 1,
+			wantSize:     3,
+		},
+		{
+			name:         "after_progress",
+			message:      "Placing TakeProfit trade (2 out of 3) 30%. Price: 0.2361 USDT Size: 77.4408 USDT (328.0 DOGE)",
+			wantPct:      30,
+			wantPosition: 2,
+			wantSize:     3,
+		},
+		{
+			name:         "decimal",
+			message:      "Placing TakeProfit trade (3 out of 3), 20.5%. Price: 0.2378 USDT Size: 53.02 USDT (223.0 DOGE)",
+			wantPct:      20.5,
+			wantPosition: 3,
+			wantSize:     3,
+		},
+		{
+			name:    "single_take_profit",
+			message: "Placing TakeProfit trade.  Price: 0.23445 USDT Size: 256.4883 USDT (1094.0 DOGE), the price should rise for 3.16% to close the trade",
+		},
+		{
+			name:         "not_a_take_profit",
+			message:      "Placing averaging order (2 out of 9) 30%. Price: 0.2201 USDT Size: 24.211 USDT (110.0 DOGE)",
+			wantPosition: 2,
+			wantSize:     9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.TakeProfitStepPercentage != tt.wantPct {
+				t.Fatalf("TakeProfitStepPercentage = %v, want %v", got.TakeProfitStepPercentage, tt.wantPct)
+			}
+			if got.OrderPosition != tt.wantPosition || got.OrderSize != tt.wantSize {
+				t.Fatalf("progress = %d/%d, want %d/%d", got.OrderPosition, got.OrderSize, tt.wantPosition, tt.wantSize)
+			}
+			// The step share is not a profit
+			if got.ProfitPercentage != 0 {
+				t.Fatalf("ProfitPercentage = %v", got.ProfitPercentage)
+			}
+		})
+	}
+}
+
+func TestParseApproxDuration(t *testing.T) {
+	ctx := Context{
+		Strategy:      StrategyLong,
+		BaseCurrency:  "DOGE",
+		QuoteCurrency: "USDT",
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		want    time.Duration
+	}{
+		{
+			name:    "about_hours",
+			message: "(USDT_DOGE): Trade completed. Profit:  +4.53711258 USDT (4.54 $) (2.0% from total volume) 💰💰💰). #profit about 5 hours",
+			want:    5 * time.Hour,
+		},
+		{
+			name:    "about_hours_fixture",
+			message: "(USDT_DOGE): Trade completed. Profit:  +4.80727389 USDT (4.81 $) (2.0% from total volume)). #profit about 23 hours",
+			want:    23 * time.Hour,
+		},
+		{
+			name:    "minutes",
+			message: "(USDT_DOGE): Trade completed. Profit:  +0.51 USDT (0.51 $) (0.5% from total volume)). #profit 40 minutes",
+			want:    40 * time.Minute,
+		},
+		{
+			name:    "single_day",
+			message: "(USDT_DOGE): Trade completed. Profit:  +9.1 USDT (9.1 $) (3.0% from total volume)). #profit about 1 day",
+			want:    24 * time.Hour,
+		},
+		{
+			name:    "days",
+			message: "(USDT_DOGE): Trade completed. Profit:  +9.1 USDT (9.1 $) (3.0% from total volume)). #profit about 3 days",
+			want:    72 * time.Hour,
+		},
+		{
+			name:    "an_hour",
+			message: "(USDT_DOGE): Trade completed. Profit:  +1.2 USDT (1.2 $) (1.0% from total volume)). #profit about an hour",
+			want:    time.Hour,
+		},
+		{
+			name:    "word_ending_in_a",
+			message: "(USDT_DOGE): Trade completed. Profit:  +1.2 USDT (1.2 $) (1.0% from total volume)). #profit data minutes",
+		},
+		{
+			name:    "no_hashtag",
+			message: "Averaging order (3 out of 9) executed. Price: 0.22815 USDT Size: 25.0965 USDT (110.0 DOGE)",
+		},
+		{
+			name:    "hashtag_without_duration",
+			message: "Stop loss 📛  -17.51435838 USDT (-17.51 $) (-4.43% from total volume) #stoploss",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.ApproxDuration != tt.want {
+				t.Fatalf("ApproxDuration = %v, want %v", got.ApproxDuration, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProfitUSDVariants(t *testing.T) {
+	ctx := Context{
+		Strategy:      StrategyLong,
+		BaseCurrency:  "DOGE",
+		QuoteCurrency: "USD",
+	}
+
+	tests := []struct {
+		name         string
+		message      string
+		wantProfit   float64
+		wantCurrency string
+		wantUSD      float64
+	}{
+		{
+			name:         "native_then_usd",
+			message:      "(USDT_DOGE): Trade completed. Profit:  +4.53711258 USDT (4.54 $) (2.0% from total volume)",
+			wantProfit:   4.53711258,
+			wantCurrency: "USDT",
+			wantUSD:      4.54,
+		},
+		{
+			name:         "equal_values",
+			message:      "(USD_DOGE): Trade completed. Profit: +4.54 USD (4.54 $) (2.0% from total volume)",
+			wantProfit:   4.54,
+			wantCurrency: "USD",
+			wantUSD:      4.54,
+		},
+		{
+			name:         "usd_then_native",
+			message:      "(USDT_DOGE): Trade completed. Profit: (4.54 $), +4.53711258 USDT (2.0% from total volume)",
+			wantProfit:   4.53711258,
+			wantCurrency: "USDT",
+			wantUSD:      4.54,
+		},
+		{
+			name:         "usd_only_paren",
+			message:      "(USD_DOGE): Trade completed. Profit: (4.54 $) (2.0% from total volume)",
+			wantProfit:   4.54,
+			wantCurrency: "USD",
+			wantUSD:      4.54,
+		},
+		{
+			name:         "usd_only_suffix",
+			message:      "(USD_DOGE): Trade completed. Profit: +4.54 $ (2.0% from total volume)",
+			wantProfit:   4.54,
+			wantCurrency: "USD",
+			wantUSD:      4.54,
+		},
+		{
+			name:         "usd_only_prefix_negative",
+			message:      "(USD_DOGE): Trade completed. Profit: -$1.25 (-0.5% from total volume)",
+			wantProfit:   -1.25,
+			wantCurrency: "USD",
+			wantUSD:      -1.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.Profit != tt.wantProfit || got.ProfitCurrency != tt.wantCurrency || got.ProfitUSD != tt.wantUSD {
+				t.Fatalf("Parse() profit = %v %q (%v $), want %v %q (%v $)",
+					got.Profit, got.ProfitCurrency, got.ProfitUSD, tt.wantProfit, tt.wantCurrency, tt.wantUSD)
+			}
+			if got.OrderType != OrderTypeSummary || got.ProfitPercentage == 0 {
+				t.Fatalf("Parse() = %q with %v%%, want a summary with its percentage", got.OrderType, got.ProfitPercentage)
+			}
+		})
+	}
+}
+
+func TestParseProfitPercentage(t *testing.T) {
+	ctx := Context{
+		Strategy:      StrategyLong,
+		BaseCurrency:  "DOGE",
+		QuoteCurrency: "USDT",
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		want    float64
+	}{
+		{
+			name:    "from_total_volume",
+			message: "(USDT_DOGE): Trade completed. Profit:  +4.53711258 USDT (4.54 $) (2.0% from total volume)",
+			want:    2.0,
+		},
+		{
+			name:    "negative_spaced",
+			message: "Stop loss 📛  -17.51435838 USDT (-17.51 $) (-4.43 % From Total Volume) #stoploss",
+			want:    -4.43,
+		},
+		{
+			name:    "tp_target_move",
+			message: "Placing TakeProfit trade. Price: 0.26 USDT Size: 28.6 USDT (110.0 DOGE), the price should rise for 3.16% to close the trade",
+		},
+		{
+			name:    "tp_target_move_in_parens",
+			message: "Placing TakeProfit trade. Price: 0.26 USDT Size: 28.6 USDT (110.0 DOGE) (3.16%)",
+		},
+		{
+			name:    "bare_percentage",
+			message: "(USDT_DOGE): Trade completed. Profit:  +4.53711258 USDT (4.54 $) (2.0%)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.ProfitPercentage != tt.want {
+				t.Fatalf("Parse() ProfitPercentage = %v, want %v", got.ProfitPercentage, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProfitPercentageSign(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		keepSign bool
+		want     float64
+	}{
+		{
+			name:    "loss_without_sign",
+			message: "(USDT_DOGE): Trade completed. Profit:  -4.53711258 USDT (-4.54 $) (2.0% from total volume). #profit about 5 hours",
+			want:    -2.0,
+		},
+		{
+			name:    "profit_with_negative_pct",
+			message: "(USDT_DOGE): Trade completed. Profit:  +4.53711258 USDT (4.54 $) (-2.0% from total volume). #profit about 5 hours",
+			want:    2.0,
+		},
+		{
+			name:    "consistent_signs",
+			message: "Stop loss 📛  -17.51435838 USDT (-17.51 $) (-4.43% from total volume) #stoploss",
+			want:    -4.43,
+		},
+		{
+			name:     "keep_sign",
+			message:  "(USDT_DOGE): Trade completed. Profit:  -4.53711258 USDT (-4.54 $) (2.0% from total volume). #profit about 5 hours",
+			keepSign: true,
+			want:     2.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := Context{Strategy: StrategyShort, BaseCurrency: "DOGE", QuoteCurrency: "USDT", KeepProfitPercentageSign: tt.keepSign}
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.ProfitPercentage != tt.want {
+				t.Fatalf("Parse() ProfitPercentage = %v, want %v", got.ProfitPercentage, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePriceCurrency(t *testing.T) {
+	ctx := Context{
+		Strategy:      StrategyLong,
+		BaseCurrency:  "DOGE",
+		QuoteCurrency: "USDT",
+	}
+
+	tests := []struct {
+		name              string
+		message           string
+		wantQuoteCurrency string
+		wantPriceCurrency string
+	}{
+		{
+			name:              "matching",
+			message:           "Base order executed. Price: 0.22758736 USDT. Size: 25.03461 USDT (110.0 DOGE)",
+			wantQuoteCurrency: "USDT",
+			wantPriceCurrency: "USDT",
+		},
+		{
+			name:              "differing_size_wins",
+			message:           "Base order executed. Price: 0.22758736 USDC. Size: 25.03461 USDT (110.0 DOGE)",
+			wantQuoteCurrency: "USDT",
+			wantPriceCurrency: "USDC",
+		},
+		{
+			name:              "price_only",
+			message:           "Placing TakeProfit trade. Price: 0.23469 BUSD",
+			wantQuoteCurrency: "BUSD",
+			wantPriceCurrency: "BUSD",
+		},
+		{
+			name:              "price_without_currency",
+			message:           "Base order executed. Price: 0.22758736 Size: 25.03461 USDC (110.0 DOGE)",
+			wantQuoteCurrency: "USDC",
+			wantPriceCurrency: "",
+		},
+		{
+			name:              "market_price",
+			message:           "Placing averaging order (9 out of 9). Price: market Size: 25.0008 USDT (110.0 DOGE)",
+			wantQuoteCurrency: "USDT",
+			wantPriceCurrency: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.QuoteCurrency != tt.wantQuoteCurrency {
+				t.Fatalf("QuoteCurrency = %q, want %q", got.QuoteCurrency, tt.wantQuoteCurrency)
+			}
+			if got.PriceCurrency != tt.wantPriceCurrency {
+				t.Fatalf("PriceCurrency = %q, want %q", got.PriceCurrency, tt.wantPriceCurrency)
+			}
+		})
+	}
+}
+
+func TestParseProgress(t *testing.T) {
+	tests := []struct {
+		in        string
+		wantPos   int
+		wantTotal int
+		wantOk    bool
+	}{
+		{in: "Placing averaging order (8 out of 9)", wantPos: 8, wantTotal: 9, wantOk: true},
+		{in: "Cancelling buy order (3 out of  9). Price: 0.22815 USDT", wantPos: 3, wantTotal: 9, wantOk: true},
+		{in: "Averaging order executed", wantOk: false},
+		{in: "(8 of 9)", wantOk: false},
+		{in: "", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		pos, total, ok := ParseProgress(tt.in)
+		if pos != tt.wantPos || total != tt.wantTotal || ok != tt.wantOk {
+			t.Fatalf("ParseProgress(%q) = %d, %d, %v, want %d, %d, %v", tt.in, pos, total, ok, tt.wantPos, tt.wantTotal, tt.wantOk)
+		}
+	}
+}
+
+func TestParseOrderRange(t *testing.T) {
+	ctx := Context{
+		Strategy:      StrategyLong,
+		BaseCurrency:  "DOGE",
+		QuoteCurrency: "USDT",
+	}
+
+	tests := []struct {
+		name string
+		msg  string
+		want Event
+	}{
+		{
+			name: "dash",
+			msg:  "Placing averaging orders (1-9)",
+			want: Event{Action: ActionPlace, OrderType: OrderTypeSafety, Side: SideBuy, Status: StatusActive, Coin: "DOGE", QuoteCurrency: "USDT", RangeStart: 1, RangeEnd: 9, MatchedRule: "averaging_order_placing_v1"},
+		},
+		{
+			name: "to",
+			msg:  "Placing averaging orders (1 to 9). Price: market",
+			want: Event{Action: ActionPlace, OrderType: OrderTypeSafety, Side: SideBuy, Status: StatusActive, Coin: "DOGE", QuoteCurrency: "USDT", IsMarket: true, RangeStart: 1, RangeEnd: 9, MatchedRule: "averaging_order_placing_v1"},
+		},
+		{
+			name: "spaced_dash",
+			msg:  "Cancelling buy orders (3 - 9)",
+			want: Event{Action: ActionCancel, OrderType: OrderTypeSafety, Side: SideBuy, Status: StatusCancelling, Coin: "DOGE", QuoteCurrency: "USDT", RangeStart: 3, RangeEnd: 9, MatchedRule: "order_range_cancelling_v1"},
+		},
+		{
+			name: "reversed_is_ignored",
+			msg:  "Placing averaging orders (9-1)",
+			want: Event{Action: ActionPlace, OrderType: OrderTypeSafety, Side: SideBuy, Status: StatusActive, Coin: "DOGE", QuoteCurrency: "USDT", MatchedRule: "averaging_order_placing_v1"},
+		},
+		{
+			name: "single_order_has_no_range",
+			msg:  "Placing averaging order (8 out of 9)",
+			want: Event{Action: ActionPlace, OrderType: OrderTypeSafety, Side: SideBuy, Status: StatusActive, Coin: "DOGE", QuoteCurrency: "USDT", OrderPosition: 8, OrderSize: 9, MatchedRule: "averaging_order_placing_v1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.msg, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(Event{}, "Text", "StrategyUsed")); diff != "" {
+				t.Fatalf("Parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		in           string
+		wantPrice    float64
+		wantCurrency string
+		wantMarket   bool
+	}{
+		{in: "Price: 0.22815 USDT Size: 25.0965 USDT", wantPrice: 0.22815, wantCurrency: "USDT"},
+		{in: "Price: market Size: 25.0008 USDT (110.0 DOGE)", wantMarket: true},
+		{in: "Price: Market Size: 25.0008 USDT (110.0 DOGE)", wantMarket: true},
+		{in: "Price: MARKET", wantMarket: true},
+		{in: "Price: 0.5", wantPrice: 0.5},
+		// The currency must directly follow the price, a later label or
+		// currency is not bound to it
+		{in: "Price: 0.22758736 Size: 25.03461 USDT (110.0 DOGE)", wantPrice: 0.22758736},
+		{in: "Price: 0.22758736, Size: 25.03461 USDT (110.0 DOGE)", wantPrice: 0.22758736},
+		{in: "Price: 0.22758736 USDC (25.03461 USDT)", wantPrice: 0.22758736, wantCurrency: "USDC"},
+		{in: "no price here"},
+		{in: "Price: $0.22815 Size: $25.0965 (110.0 DOGE)", wantPrice: 0.22815, wantCurrency: "USD"},
+		{in: "Price: 0.22815 $ Size: 25.0965 $ (110.0 DOGE)", wantPrice: 0.22815, wantCurrency: "USD"},
+		// A negative price is rejected, not stored with its currency
+		{in: "Price: -0.22815 USDT Size: 25.0965 USDT"},
+		{in: "Price: -$0.22815 Size: $25.0965"},
+	}
+
+	for _, tt := range tests {
+		price, currency, isMarket := ParsePrice(tt.in)
+		if price != tt.wantPrice || currency != tt.wantCurrency || isMarket != tt.wantMarket {
+			t.Fatalf("ParsePrice(%q) = %v, %q, %v", tt.in, price, currency, isMarket)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in           string
+		base         string
+		wantQuoteVol float64
+		wantQuoteCur string
+		wantBaseVol  float64
+		wantBaseCur  string
+	}{
+		{in: "Size: 25.0965 USDT (110.0 DOGE)", base: "DOGE", wantQuoteVol: 25.0965, wantQuoteCur: "USDT", wantBaseVol: 110, wantBaseCur: "DOGE"},
+		{in: "Size: 25.0965 USDT (110.0 DOGE)", wantQuoteVol: 25.0965, wantQuoteCur: "USDT", wantBaseVol: 110, wantBaseCur: "DOGE"},
+		{in: "Size: 110.0 DOGE", base: "DOGE", wantBaseVol: 110, wantBaseCur: "DOGE"},
+		{in: "Size: 110.0 DOGE", wantQuoteVol: 110, wantQuoteCur: "DOGE"},
+		{in: "Price: 0.22815 USDT", base: "DOGE"},
+		{in: "Size: $25.0965 (110.0 DOGE)", base: "DOGE", wantQuoteVol: 25.0965, wantQuoteCur: "USD", wantBaseVol: 110, wantBaseCur: "DOGE"},
+		{in: "Size: 25.0965 $ (110.0 DOGE)", base: "DOGE", wantQuoteVol: 25.0965, wantQuoteCur: "USD", wantBaseVol: 110, wantBaseCur: "DOGE"},
+		{in: "Size: $ 25.0965", base: "DOGE", wantQuoteVol: 25.0965, wantQuoteCur: "USD"},
+		{in: "Size: 25.0965 USDT (110.0 DOGE)). (4.54 USDT)", base: "DOGE", wantQuoteVol: 25.0965, wantQuoteCur: "USDT", wantBaseVol: 110, wantBaseCur: "DOGE"},
+		{in: "Size: 110.0 DOGE) (4.54 USDT)", base: "DOGE", wantBaseVol: 110, wantBaseCur: "DOGE"},
+		{in: "Size: 28.6 USDT (110.0 DOGE) (Risk reduction 1.1366 USDT)", base: "DOGE", wantQuoteVol: 28.6, wantQuoteCur: "USDT", wantBaseVol: 110, wantBaseCur: "DOGE"},
+		{in: "Size: 28.6 USDT (110.0 DOGE) (Reduction: 1.1366 USDT)", base: "DOGE", wantQuoteVol: 28.6, wantQuoteCur: "USDT", wantBaseVol: 110, wantBaseCur: "DOGE"},
+		{in: "Size: 110.0 DOGE (Risk 1.1366 USDT)", base: "DOGE", wantBaseVol: 110, wantBaseCur: "DOGE"},
+		{in: "Size: -25.0965 USDT (110.0 DOGE)", base: "DOGE", wantQuoteCur: "USDT", wantBaseVol: 110, wantBaseCur: "DOGE"},
+		{in: "Size: 25.0965 USDT (-110.0 DOGE)", base: "DOGE", wantQuoteVol: 25.0965, wantQuoteCur: "USDT", wantBaseCur: "DOGE"},
+	}
+
+	for _, tt := range tests {
+		quoteVol, quoteCur, baseVol, baseCur := ParseSize(tt.in, tt.base)
+		if quoteVol != tt.wantQuoteVol || quoteCur != tt.wantQuoteCur || baseVol != tt.wantBaseVol || baseCur != tt.wantBaseCur {
+			t.Fatalf("ParseSize(%q, %q) = %v, %q, %v, %q", tt.in, tt.base, quoteVol, quoteCur, baseVol, baseCur)
+		}
+	}
+}
+
+func TestParseTakeProfitLabeledParens(t *testing.T) {
+	ctx := Context{Strategy: StrategyLong, BaseCurrency: "DOGE", QuoteCurrency: "USDT"}
+
+	tests := []struct {
+		name     string
+		message  string
+		wantSize float64
+		wantRisk float64
+	}{
+		{
+			name:     "risk_reduction_after_base",
+			message:  "Placing TakeProfit trade (1 out of 3) 50%. Price: 0.26 USDT Size: 28.6 USDT (110.0 DOGE) (Risk reduction 1.1366 USDT)",
+			wantSize: 110,
+			wantRisk: 1.1366,
+		},
+		{
+			name:     "risk_reduction_before_base",
+			message:  "Placing TakeProfit trade (2 out of 3) 25%. Price: 0.27 USDT Size: 14.85 USDT (Risk reduction 1.1366 USDT) (55.0 DOGE)",
+			wantSize: 55,
+			wantRisk: 1.1366,
+		},
+		{
+			name:     "single_word_label_after_base",
+			message:  "Placing TakeProfit trade (3 out of 3) 25%. Price: 0.28 USDT Size: 15.4 USDT (55.0 DOGE) (Reduction 0.5 USDT)",
+			wantSize: 55,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.OrderType != OrderTypeTakeProfit || got.Size != tt.wantSize || got.Coin != "DOGE" {
+				t.Fatalf("Parse() = %v Size %v %q, want %v Size %v DOGE", got.OrderType, got.Size, got.Coin, OrderTypeTakeProfit, tt.wantSize)
+			}
+			if got.RiskReduction != tt.wantRisk {
+				t.Fatalf("Parse() RiskReduction = %v, want %v", got.RiskReduction, tt.wantRisk)
+			}
+		})
+	}
+}
+
+func TestParseSummaryStrayParens(t *testing.T) {
+	messages := []string{
+		"(USDT_DOGE): Trade completed. Profit:  +4.80727389 USDT (4.81 $) (2.0% from total volume)). #profit about 23 hours",
+		"(USDT_DOGE): Trade completed. Profit:  +4.53711258 USDT (4.54 $) (2.0% from total volume) 💰💰💰). #profit about 5 hours",
+	}
+
+	for _, msg := range messages {
+		event, err := Parse(msg, Context{Strategy: StrategyLong})
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", msg, err)
+		}
+		if event.Size != 0 || event.QuoteVolume != 0 || event.Coin != "" {
+			t.Fatalf("Parse(%q) Size = %v, QuoteVolume = %v, Coin = %q, want none", msg, event.Size, event.QuoteVolume, event.Coin)
+		}
+		if event.Profit == 0 {
+			t.Fatalf("Parse(%q) Profit = 0", msg)
+		}
+	}
+}
+
+func TestParseTradeCompletedPairPrefix(t *testing.T) {
+	ctx := Context{Strategy: StrategyLong, BaseCurrency: "ADA", QuoteCurrency: "BTC"}
+	const summary = "Trade completed. Profit: +0.00001234 BTC (1.05 $) (1.2% from total volume). #profit about 2 hours"
+
+	without, err := Parse(summary, ctx)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if without.Action != ActionCompleted || without.OrderType != OrderTypeSummary {
+		t.Fatalf("Parse() = %v %v, want %v %v", without.Action, without.OrderType, ActionCompleted, OrderTypeSummary)
+	}
+	if without.Coin != "ADA" || without.QuoteCurrency != "BTC" {
+		t.Fatalf("Parse() Coin = %q, QuoteCurrency = %q, want the context's ADA, BTC", without.Coin, without.QuoteCurrency)
+	}
+
+	with, err := Parse("(BTC_ADA): "+summary, ctx)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if diff := cmp.Diff(without, with, cmpopts.IgnoreFields(Event{}, "Text")); diff != "" {
+		t.Fatalf("Parse() with and without the pair prefix disagree (-without +with):\n%s", diff)
+	}
+}
+
+func TestParseWithTrace(t *testing.T) {
+	ctx := Context{
+		Strategy:      StrategyLong,
+		BaseCurrency:  "DOGE",
+		QuoteCurrency: "USDT",
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		want    int
+	}{
+		{
+			name:    "placing_averaging",
+			message: "Placing averaging order (9 out of 9). Price: market Size: 25.0008 USDT (110.0 DOGE)",
+			want:    5, // action, order type, side, price, size
+		},
+		{
+			name:    "stoploss_summary",
+			message: "Stop loss 📛  -17.51435838 USDT (-17.51 $) (-4.43% from total volume) #stoploss",
+			want:    4, // action, order type, side, profit
+		},
+		{
+			name:    "trade_completed_summary",
+			message: "(USDT_DOGE): Trade completed. Profit:  +4.53711258 USDT (4.54 $) (2.0% from total volume) 💰💰💰). #profit about 5 hours",
+			want:    3, // action, order type, profit
+		},
+		{
+			name:    "unrecognised",
+			message: "Something we have never seen before",
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, trace, err := ParseWithTrace(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("ParseWithTrace() error = %v", err)
+			}
+			if trace.FieldsParsed != tt.want {
+				t.Fatalf("ParseWithTrace() FieldsParsed = %d, want %d (%#v)", trace.FieldsParsed, tt.want, event)
+			}
+
+			plain, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if diff := cmp.Diff(plain, event); diff != "" {
+				t.Fatalf("Parse() and ParseWithTrace() disagree (-parse +trace):\n%s", diff)
+			}
+		})
+	}
+
+	if _, _, err := ParseWithTrace("   ", ctx); err != ErrEmptyMessage {
+		t.Fatalf("ParseWithTrace() error = %v, want %v", err, ErrEmptyMessage)
+	}
+}
+
+func TestParseCryptoQuoted(t *testing.T) {
+	ctx := Context{
+		Strategy:      StrategyLong,
+		BaseCurrency:  "ADA",
+		QuoteCurrency: "BTC",
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		want    Event
+	}{
+		{
+			name:    "placing_averaging_btc",
+			message: "Placing averaging order (2 out of 5). Price: 0.00000234 BTC Size: 0.00119808 BTC (512.0 ADA)",
+			want: Event{
+				Action:        ActionPlace,
+				OrderType:     OrderTypeSafety,
+				Side:          SideBuy,
+				Status:        StatusActive,
+				OrderPosition: 2,
+				OrderSize:     5,
+				Coin:          "ADA",
+				QuoteCurrency: "BTC",
+				QuoteVolume:   0.00119808,
+				Price:         0.00000234,
+				PriceCurrency: "BTC",
+				Size:          512.0,
+				MatchedRule:   "averaging_order_placing_v1",
+			},
+		},
+		{
+			name:    "executed_base_btc",
+			message: "Base order executed.  Price: 0.00000234 BTC.  Size: 0.00119808 BTC (512.0 ADA)",
+			want: Event{
+				Action:        ActionExecute,
+				OrderType:     OrderTypeBase,
+				Side:          SideBuy,
+				Status:        StatusFilled,
+				Coin:          "ADA",
+				QuoteCurrency: "BTC",
+				QuoteVolume:   0.00119808,
+				Price:         0.00000234,
+				PriceCurrency: "BTC",
+				Size:          512.0,
+				MatchedRule:   "base_order_executed_v1",
+			},
+		},
+		{
+			name:    "trade_completed_btc",
+			message: "(BTC_ADA): Trade completed. Profit:  +0.00000123 BTC (0.08 $) (1.2% from total volume) 💰). #profit about 2 hours",
+			want: Event{
+				Action:           ActionCompleted,
+				OrderType:        OrderTypeSummary,
+				Side:             SideUnknown,
+				Status:           StatusFinished,
+				Coin:             "ADA",
+				QuoteCurrency:    "BTC",
+				Profit:           0.00000123,
+				ProfitCurrency:   "BTC",
+				ProfitUSD:        0.08,
+				ProfitPercentage: 1.2,
+				ApproxDuration:   2 * time.Hour,
+				MatchedRule:      "trade_completed_v1",
+			},
+		},
+		{
+			name:    "placing_tp_eth",
+			message: "Placing TakeProfit trade.  Price: 0.0000512 ETH Size: 0.0262144 ETH (512.0 ADA), the price should rise for 3.0% to close the trade",
+			want: Event{
+				Action:        ActionPlace,
+				OrderType:     OrderTypeTakeProfit,
+				Side:          SideSell,
+				Status:        StatusActive,
+				Coin:          "ADA",
+				QuoteCurrency: "ETH",
+				QuoteVolume:   0.0262144,
+				Price:         0.0000512,
+				PriceCurrency: "ETH",
+				Size:          512.0,
+				MatchedRule:   "take_profit_placing_v1",
+			},
+		},
+		{
+			name:    "placing_averaging_scientific",
+			message: "Placing averaging order (3 out of 5). Price: 1.23e-7 BTC Size: 6.2976e-5 BTC (512.0 ADA)",
+			want: Event{
+				Action:        ActionPlace,
+				OrderType:     OrderTypeSafety,
+				Side:          SideBuy,
+				Status:        StatusActive,
+				OrderPosition: 3,
+				OrderSize:     5,
+				Coin:          "ADA",
+				QuoteCurrency: "BTC",
+				QuoteVolume:   6.2976e-5,
+				Price:         1.23e-7,
+				PriceCurrency: "BTC",
+				Size:          512.0,
+				MatchedRule:   "averaging_order_placing_v1",
+			},
+		},
+		{
+			name:    "base_size_scientific",
+			message: "Base order executed. Price: 2.5E-6 BTC Size: 0.00128 BTC (5.12e+2 ADA)",
+			want: Event{
+				Action:        ActionExecute,
+				OrderType:     OrderTypeBase,
+				Side:          SideBuy,
+				Status:        StatusFilled,
+				Coin:          "ADA",
+				QuoteCurrency: "BTC",
+				QuoteVolume:   0.00128,
+				Price:         2.5e-6,
+				PriceCurrency: "BTC",
+				Size:          512.0,
+				MatchedRule:   "base_order_executed_v1",
+			},
+		},
+		{
+			name:    "trade_completed_scientific",
+			message: "(BTC_ADA): Trade completed. Profit:  +1.23e-7 BTC (0.01 $) (1.2% from total volume) 💰). #profit about 2 hours",
+			want: Event{
+				Action:           ActionCompleted,
+				OrderType:        OrderTypeSummary,
+				Side:             SideUnknown,
+				Status:           StatusFinished,
+				Coin:             "ADA",
+				QuoteCurrency:    "BTC",
+				Profit:           1.23e-7,
+				ProfitCurrency:   "BTC",
+				ProfitUSD:        0.01,
+				ProfitPercentage: 1.2,
+				ApproxDuration:   2 * time.Hour,
+				MatchedRule:      "trade_completed_v1",
+			},
+		},
+		{
+			// A currency starting with an E is not an exponent
+			name:    "currency_after_number",
+			message: "Placing averaging order (4 out of 5). Price: 0.3 EUR Size: 153.6EUR (512.0 ADA)",
+			want: Event{
+				Action:        ActionPlace,
+				OrderType:     OrderTypeSafety,
+				Side:          SideBuy,
+				Status:        StatusActive,
+				OrderPosition: 4,
+				OrderSize:     5,
+				Coin:          "ADA",
+				QuoteCurrency: "EUR",
+				QuoteVolume:   153.6,
+				Price:         0.3,
+				PriceCurrency: "EUR",
+				Size:          512.0,
+				MatchedRule:   "averaging_order_placing_v1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			// No tolerance: tiny crypto-quoted values must survive parsing exactly.
+			diff := cmp.Diff(
+				tt.want,
+				got,
+				cmpopts.IgnoreFields(Event{}, "Text", "StrategyUsed"),
+			)
+			if diff != "" {
+				t.Fatalf("Parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseNegativeAmounts(t *testing.T) {
+	ctx := Context{Strategy: StrategyLong, BaseCurrency: "DOGE", QuoteCurrency: "USDT"}
+
+	event, err := Parse("Base order executed. Price: -0.22758736 USDT Size: 25.03461 USDT (110.0 DOGE)", ctx)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if event.Price != 0 || event.PriceCurrency != "" || event.IsMarket {
+		t.Fatalf("Parse() price = %v %q (market %v), want it rejected", event.Price, event.PriceCurrency, event.IsMarket)
+	}
+	if event.QuoteVolume != 25.03461 || event.Size != 110 {
+		t.Fatalf("Parse() size = %v (%v), want 25.03461 (110)", event.QuoteVolume, event.Size)
+	}
+
+	event, err = Parse("Base order executed. Price: 0.22758736 USDT Size: -25.03461 USDT (-110.0 DOGE)", ctx)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if event.Price != 0.22758736 || event.QuoteVolume != 0 || event.Size != 0 {
+		t.Fatalf("Parse() = price %v, size %v (%v), want the negative sizes rejected", event.Price, event.QuoteVolume, event.Size)
+	}
+}
+
+func TestFirstSentence(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "Base order executed. Price: 0.22758736 USDT", want: "Base order executed"},
+		{in: "Base order executed.", want: "Base order executed"},
+		{in: "Base order executed", want: "Base order executed"},
+		// A ". " right after a number doesn't end the sentence
+		{in: "Averaging order of 25.0. USDT executed. Price: 0.2 USDT", want: "Averaging order of 25.0. USDT executed"},
+		{in: "Price: 0.22758736. Size: 25.03461 USDT", want: "Price: 0.22758736. Size: 25.03461 USDT"},
+		// Neither do abbreviations
+		{in: "Base order executed in U.S. dollars. Price: 0.2 USD", want: "Base order executed in U.S. dollars"},
+		{in: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := firstSentence(tt.in); got != tt.want {
+			t.Fatalf("firstSentence(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseNumberBeforeSentenceBreak(t *testing.T) {
+	ctx := Context{Strategy: StrategyLong, BaseCurrency: "DOGE", QuoteCurrency: "USDT"}
+
+	event, err := Parse("Averaging order (2 out of 9) of 25.0. USDT executed. Price: 0.2 USDT Size: 25.0 USDT (125.0 DOGE)", ctx)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if event.Action != ActionExecute || event.OrderType != OrderTypeSafety || event.Status != StatusFilled {
+		t.Fatalf("Parse() = %s %s %s, want a filled safety order execute", event.Action, event.OrderType, event.Status)
+	}
+	if event.Price != 0.2 || event.Size != 125 {
+		t.Fatalf("Parse() price = %v, size = %v, want 0.2, 125", event.Price, event.Size)
+	}
+}
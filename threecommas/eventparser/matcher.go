@@ -0,0 +1,76 @@
+package eventparser
+
+import "strings"
+
+// Matcher recognises a message format on its own. Match receives the
+// normalized message (emoji and hashtags stripped, whitespace collapsed) and
+// reports whether it handled it.
+type Matcher interface {
+	Match(normalized string) (Event, bool)
+}
+
+// MatcherFunc adapts a plain function to the Matcher interface.
+type MatcherFunc func(normalized string) (Event, bool)
+
+// Match calls f(normalized).
+func (f MatcherFunc) Match(normalized string) (Event, bool) {
+	return f(normalized)
+}
+
+// ParserOptions configures ParseWith.
+type ParserOptions struct {
+	// Before matchers are consulted ahead of the built-in logic, the first
+	// one that matches wins.
+	Before []Matcher
+	// After matchers are consulted only when the built-in logic could not
+	// classify the message (the Action is unknown).
+	After []Matcher
+}
+
+// ParseWith behaves like Parse but consults the matchers in opts around the
+// built-in logic. Events returned by a matcher get their Text, Coin and
+// QuoteCurrency filled in from the message and ctx when left empty.
+func ParseWith(message string, ctx Context, opts ParserOptions) (Event, error) {
+	raw := strings.TrimSpace(message)
+	if raw == "" {
+		return Event{}, ErrEmptyMessage
+	}
+	normalized := normalize(raw)
+
+	if event, ok := match(opts.Before, raw, normalized, ctx); ok {
+		return event, nil
+	}
+
+	event, err := Parse(message, ctx)
+	if err != nil || event.Action != ActionUnknown {
+		return event, err
+	}
+
+	if matched, ok := match(opts.After, raw, normalized, ctx); ok {
+		return matched, nil
+	}
+	return event, nil
+}
+
+func match(matchers []Matcher, raw, normalized string, ctx Context) (Event, bool) {
+	for _, m := range matchers {
+		if m == nil {
+			continue
+		}
+		event, ok := m.Match(normalized)
+		if !ok {
+			continue
+		}
+		if event.Text == "" {
+			event.Text = raw
+		}
+		if event.Coin == "" {
+			event.Coin = ctx.BaseCurrency
+		}
+		if event.QuoteCurrency == "" {
+			event.QuoteCurrency = ctx.QuoteCurrency
+		}
+		return event, true
+	}
+	return Event{}, false
+}
@@ -0,0 +1,108 @@
+package eventparser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseWith(t *testing.T) {
+	ctx := Context{
+		Strategy:      StrategyLong,
+		BaseCurrency:  "DOGE",
+		QuoteCurrency: "USDT",
+	}
+
+	// A made-up message format the built-in parser does not know about.
+	moon := MatcherFunc(func(normalized string) (Event, bool) {
+		if !strings.HasPrefix(normalized, "Launching rocket") {
+			return Event{}, false
+		}
+		return Event{Action: ActionPlace, OrderType: OrderTypeBase, Side: SideBuy, Status: StatusActive}, true
+	})
+	// Claims every message, used to verify the Before/After precedence.
+	everything := MatcherFunc(func(string) (Event, bool) {
+		return Event{Action: ActionFinished}, true
+	})
+
+	tests := []struct {
+		name    string
+		message string
+		opts    ParserOptions
+		want    Event
+	}{
+		{
+			name:    "after_handles_unknown",
+			message: "🚀 Launching rocket to the moon #custom",
+			opts:    ParserOptions{After: []Matcher{moon}},
+			want: Event{
+				Action:        ActionPlace,
+				OrderType:     OrderTypeBase,
+				Side:          SideBuy,
+				Status:        StatusActive,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				Text:          "🚀 Launching rocket to the moon #custom",
+			},
+		},
+		{
+			name:    "after_skipped_for_known",
+			message: "Base order executed. Price: 0.25 USDT. Size: 25.0 USDT (100.0 DOGE)",
+			opts:    ParserOptions{After: []Matcher{everything}},
+			want: Event{
+				Action:        ActionExecute,
+				OrderType:     OrderTypeBase,
+				Side:          SideBuy,
+				Status:        StatusFilled,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   25,
+				Price:         0.25,
+				PriceCurrency: "USDT",
+				Size:          100,
+				StrategyUsed:  StrategyLong,
+				MatchedRule:   "base_order_executed_v1",
+				Text:          "Base order executed. Price: 0.25 USDT. Size: 25.0 USDT (100.0 DOGE)",
+			},
+		},
+		{
+			name:    "before_overrides_builtin",
+			message: "Base order executed. Price: 0.25 USDT. Size: 25.0 USDT (100.0 DOGE)",
+			opts:    ParserOptions{Before: []Matcher{nil, moon, everything}},
+			want: Event{
+				Action:        ActionFinished,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				Text:          "Base order executed. Price: 0.25 USDT. Size: 25.0 USDT (100.0 DOGE)",
+			},
+		},
+		{
+			name:    "no_match_falls_back",
+			message: "Something we have never seen before",
+			opts:    ParserOptions{Before: []Matcher{moon}, After: []Matcher{moon}},
+			want: Event{
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				StrategyUsed:  StrategyLong,
+				Text:          "Something we have never seen before",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWith(tt.message, ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("ParseWith() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("ParseWith() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := ParseWith("  ", ctx, ParserOptions{Before: []Matcher{everything}}); err != ErrEmptyMessage {
+		t.Fatalf("ParseWith() error = %v, want %v", err, ErrEmptyMessage)
+	}
+}
@@ -0,0 +1,78 @@
+package eventparser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EventSource tells which kind of 3commas entity emitted an event.
+type EventSource string
+
+const (
+	EventSourceBot        EventSource = ""
+	EventSourceSmartTrade EventSource = "smart_trade"
+)
+
+// smartTradeStepRe matches a smart trade step, “Step 1 (buy)” or
+// “Step 2 out of 3 (sell)”.
+var smartTradeStepRe = regexp.MustCompile(`(?i)\bstep\s+(\d+)(?:\s+out of\s+(\d+))?\s*\((buy|sell)\)`)
+
+// ParseSmartTrade analyses a smart trade event message, e.g. "Step 1 (buy)
+// executed. Price: 0.2275 USDT Size: 25.03 USDT (110.0 DOGE)". The step number
+// is returned as the OrderPosition, the number of steps (when given) as the
+// OrderSize and the Side is taken from the step. Smart trade steps have no
+// OrderType. Messages without a step are parsed for their action, price, size
+// and profit only.
+func ParseSmartTrade(message string, ctx Context) (Event, error) {
+	raw := strings.TrimSpace(message)
+	if raw == "" {
+		return Event{}, ErrEmptyMessage
+	}
+	normalized := normalize(raw)
+
+	event := Event{
+		Source: EventSourceSmartTrade,
+		Text:   raw,
+	}
+	action, _, actionRule := classifyAction(firstSentence(normalized))
+	event.Action = action
+	event.Status = inferStatus(event.Action)
+
+	if match := smartTradeStepRe.FindStringSubmatch(normalized); match != nil {
+		event.OrderPosition, _ = strconv.Atoi(match[1])
+		event.OrderSize, _ = strconv.Atoi(match[2])
+		event.Side = SideBuy
+		if strings.EqualFold(match[3], "sell") {
+			event.Side = SideSell
+		}
+		event.MatchedRule = matchedRule("smart_trade_step", actionRule)
+	}
+
+	if price, currency, isMarket := ParsePrice(normalized); currency != "" || isMarket {
+		event.Price = price
+		event.PriceCurrency = currency
+		event.IsMarket = isMarket
+		event.QuoteCurrency = currency
+	}
+	if quoteVol, quoteCur, baseVol, baseCur := ParseSize(normalized, ctx.BaseCurrency); quoteVol > 0 || baseVol > 0 {
+		event.QuoteVolume = quoteVol
+		if quoteCur != "" {
+			event.QuoteCurrency = quoteCur
+		}
+		event.Size = baseVol
+		event.Coin = baseCur
+	}
+	event.Profit, event.ProfitCurrency, event.ProfitUSD, event.ProfitPercentage = parseProfit(normalized)
+	if !ctx.KeepProfitPercentageSign {
+		event.ProfitPercentage = matchSign(event.ProfitPercentage, event.Profit)
+	}
+
+	if event.Coin == "" {
+		event.Coin = ctx.BaseCurrency
+	}
+	if event.QuoteCurrency == "" {
+		event.QuoteCurrency = ctx.QuoteCurrency
+	}
+	return event, nil
+}
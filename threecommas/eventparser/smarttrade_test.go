@@ -0,0 +1,125 @@
+package eventparser
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParseSmartTrade(t *testing.T) {
+	ctx := Context{BaseCurrency: "DOGE", QuoteCurrency: "USDT"}
+
+	tests := []struct {
+		name    string
+		message string
+		want    Event
+	}{
+		{
+			name:    "buy_step_executed",
+			message: "Step 1 (buy) executed. Price: 0.22758736 USDT Size: 25.03461 USDT (110.0 DOGE)",
+			want: Event{
+				Action:        ActionExecute,
+				Side:          SideBuy,
+				Status:        StatusFilled,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   25.03461,
+				Price:         0.22758736,
+				PriceCurrency: "USDT",
+				Size:          110,
+				OrderPosition: 1,
+				MatchedRule:   "smart_trade_step_executed_v1",
+			},
+		},
+		{
+			name:    "placing_sell_step",
+			message: "Placing step 2 (sell). Price: 0.235 USDT Size: 12.925 USDT (55.0 DOGE)",
+			want: Event{
+				Action:        ActionPlace,
+				Side:          SideSell,
+				Status:        StatusActive,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   12.925,
+				Price:         0.235,
+				PriceCurrency: "USDT",
+				Size:          55,
+				OrderPosition: 2,
+				MatchedRule:   "smart_trade_step_placing_v1",
+			},
+		},
+		{
+			name:    "sell_step_out_of_cancelled",
+			message: "Step 3 out of 3 (sell) cancelled. Price: market Size: 13.2 USDT (55.0 DOGE)",
+			want: Event{
+				Action:        ActionCancelled,
+				Side:          SideSell,
+				Status:        StatusCancelled,
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+				QuoteVolume:   13.2,
+				IsMarket:      true,
+				Size:          55,
+				OrderPosition: 3,
+				OrderSize:     3,
+				MatchedRule:   "smart_trade_step_cancelled_v1",
+			},
+		},
+		{
+			name:    "sell_step_with_profit",
+			message: "Step 2 (SELL) executed. Price: 0.235 USDT Size: 12.925 USDT (55.0 DOGE). Profit: +0.4 USDT (0.4 $) (3.2% from total volume)",
+			want: Event{
+				Action:           ActionExecute,
+				Side:             SideSell,
+				Status:           StatusFilled,
+				Coin:             "DOGE",
+				QuoteCurrency:    "USDT",
+				QuoteVolume:      12.925,
+				Price:            0.235,
+				PriceCurrency:    "USDT",
+				Size:             55,
+				OrderPosition:    2,
+				Profit:           0.4,
+				ProfitCurrency:   "USDT",
+				ProfitUSD:        0.4,
+				ProfitPercentage: 3.2,
+				MatchedRule:      "smart_trade_step_executed_v1",
+			},
+		},
+		{
+			name:    "without_step",
+			message: "Smart trade closed manually",
+			want: Event{
+				Coin:          "DOGE",
+				QuoteCurrency: "USDT",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSmartTrade(tt.message, ctx)
+			if err != nil {
+				t.Fatalf("ParseSmartTrade() error = %v", err)
+			}
+			if got.Source != EventSourceSmartTrade {
+				t.Fatalf("ParseSmartTrade() Source = %q, want %q", got.Source, EventSourceSmartTrade)
+			}
+			tt.want.Source = EventSourceSmartTrade
+			if diff := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(Event{}, "Text")); diff != "" {
+				t.Fatalf("ParseSmartTrade() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := ParseSmartTrade("  ", ctx); err != ErrEmptyMessage {
+		t.Fatalf("ParseSmartTrade() error = %v, want %v", err, ErrEmptyMessage)
+	}
+
+	// Bot events keep the bot source
+	event, err := Parse("Base order executed. Price: 0.25 USDT Size: 25.0 USDT (100.0 DOGE)", ctx)
+	if err != nil || event.Source != EventSourceBot {
+		t.Fatalf("Parse() Source = %q, err = %v, want the bot source", event.Source, err)
+	}
+}
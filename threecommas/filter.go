@@ -1,6 +1,9 @@
 package threecommas
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 func Filter[T any](s []T, keep func(T) bool) []T {
 	result := make([]T, 0, len(s))
@@ -29,3 +32,114 @@ func MarketOrderFilter(orderType MarketOrderOrderType) func(o MarketOrder) bool
 		return o.OrderType == orderType
 	}
 }
+
+// BotEventFilterOrderType keeps the events of orders of the given deal order
+// type, e.g. MarketOrderDealOrderTypeSafety.
+func BotEventFilterOrderType(orderType MarketOrderDealOrderType) func(e BotEvent) bool {
+	return func(e BotEvent) bool {
+		return e.OrderType == orderType
+	}
+}
+
+// BotEventFilterAction keeps the events with the given action.
+func BotEventFilterAction(action BotEventAction) func(e BotEvent) bool {
+	return func(e BotEvent) bool {
+		return e.Action == action
+	}
+}
+
+// BotEventFilterStatus keeps the events with the given order status.
+func BotEventFilterStatus(status MarketOrderStatusString) func(e BotEvent) bool {
+	return func(e BotEvent) bool {
+		return e.Status == status
+	}
+}
+
+// BotEventFilterCreatedAtAfter keeps the events created after u.
+func BotEventFilterCreatedAtAfter(u time.Time) func(e BotEvent) bool {
+	return func(e BotEvent) bool {
+		return e.CreatedAt.After(u)
+	}
+}
+
+// OpenDeals returns the deals that have not reached a terminal status yet.
+func OpenDeals(deals []Deal) []Deal {
+	return Filter(deals, func(d Deal) bool {
+		return !d.Status.IsTerminal()
+	})
+}
+
+// ClosedDeals returns the deals that reached a terminal status.
+func ClosedDeals(deals []Deal) []Deal {
+	return Filter(deals, func(d Deal) bool {
+		return d.Status.IsTerminal()
+	})
+}
+
+// DealFilterProfitable keeps the deals that closed with a profit, see
+// Deal.IsProfitable.
+func DealFilterProfitable() func(d Deal) bool {
+	return func(d Deal) bool {
+		profitable, known := d.IsProfitable()
+		return known && profitable
+	}
+}
+
+// TotalOpenExposure sums the BoughtQuoteVolume of the open deals per quote
+// currency, keyed by the upper-cased FromCurrency. Closed deals and deals
+// without a known bought volume are skipped.
+func TotalOpenExposure(deals []Deal) map[string]float64 {
+	exposure := make(map[string]float64)
+	for i := range deals {
+		if deals[i].Status.IsTerminal() {
+			continue
+		}
+		volume, ok := deals[i].BoughtQuoteVolume()
+		if !ok {
+			continue
+		}
+		exposure[strings.ToUpper(deals[i].FromCurrency)] += volume
+	}
+	return exposure
+}
+
+// DealStats aggregates the closed deals passed to AggregateDealStats.
+type DealStats struct {
+	// Count is the number of closed deals with a known realized profit.
+	Count int
+	// Completed is the number of those deals that completed (as opposed to
+	// e.g. failed or panic sold).
+	Completed int
+	// Wins is the number of deals closed with a positive profit.
+	Wins          int
+	TotalProfit   float64
+	AverageProfit float64
+	// WinRate is Wins divided by Count, between 0 and 1.
+	WinRate float64
+}
+
+// AggregateDealStats computes statistics over the closed deals using their
+// RealizedProfit. Open deals and deals without a known profit are skipped.
+func AggregateDealStats(deals []Deal) DealStats {
+	var stats DealStats
+	for i := range deals {
+		profit, ok := deals[i].RealizedProfit()
+		if !ok {
+			continue
+		}
+		stats.Count++
+		if deals[i].Status == DealStatusCompleted {
+			stats.Completed++
+		}
+		if profit > 0 {
+			stats.Wins++
+		}
+		stats.TotalProfit += profit
+	}
+
+	if stats.Count > 0 {
+		stats.AverageProfit = stats.TotalProfit / float64(stats.Count)
+		stats.WinRate = float64(stats.Wins) / float64(stats.Count)
+	}
+	return stats
+}
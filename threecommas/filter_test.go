@@ -0,0 +1,113 @@
+package threecommas
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mixedDeals() []Deal {
+	return []Deal{
+		{Id: 1, Status: DealStatusBought},
+		{Id: 2, Status: DealStatusCompleted},
+		{Id: 3, Status: "created"},
+		{Id: 4, Status: DealStatusFailed},
+		{Id: 5, Status: "panic_sold"},
+		{Id: 6, Status: "base_order_placed"},
+	}
+}
+
+func dealIDs(deals []Deal) []int {
+	ids := make([]int, 0, len(deals))
+	for _, d := range deals {
+		ids = append(ids, d.Id)
+	}
+	return ids
+}
+
+func TestBotEventFilters(t *testing.T) {
+	var deal Deal
+	require.NoError(t, json.Unmarshal([]byte(exampleDeal), &deal))
+	events := deal.Events()
+	require.Len(t, events, 50)
+
+	require.Len(t, Filter(events, BotEventFilterOrderType(MarketOrderDealOrderTypeBase)), 2)
+	require.Len(t, Filter(events, BotEventFilterOrderType(MarketOrderDealOrderTypeSafety)), 18)
+	require.Len(t, Filter(events, BotEventFilterAction(BotEventActionPlace)), 20)
+	require.Len(t, Filter(events, BotEventFilterAction(BotEventActionCancelled)), 9)
+	require.Len(t, Filter(events, BotEventFilterStatus(Filled)), 10)
+	require.Len(t, Filter(events, BotEventFilterStatus(Cancelled)), 9)
+
+	first, last := events[0].CreatedAt, events[len(events)-1].CreatedAt
+	require.Len(t, Filter(events, BotEventFilterCreatedAtAfter(first.Add(-time.Second))), 50)
+	require.Empty(t, Filter(events, BotEventFilterCreatedAtAfter(last)))
+
+	// Predicates combine, e.g. the executed safety orders
+	executedSafety := Filter(Filter(events, BotEventFilterOrderType(MarketOrderDealOrderTypeSafety)), BotEventFilterStatus(Filled))
+	require.NotEmpty(t, executedSafety)
+	for _, ev := range executedSafety {
+		require.Equal(t, BotEventActionExecute, ev.Action)
+	}
+}
+
+func TestOpenDeals(t *testing.T) {
+	require.Equal(t, []int{1, 3, 6}, dealIDs(OpenDeals(mixedDeals())))
+	require.Empty(t, OpenDeals(nil))
+}
+
+func TestClosedDeals(t *testing.T) {
+	require.Equal(t, []int{2, 4, 5}, dealIDs(ClosedDeals(mixedDeals())))
+	require.Empty(t, ClosedDeals(nil))
+}
+
+func TestDealFilterProfitable(t *testing.T) {
+	deals := []Deal{
+		{Id: 1, Status: DealStatusCompleted, FinalProfit: "1.5"},
+		{Id: 2, Status: DealStatusCompleted, FinalProfit: "-0.5"},
+		{Id: 3, Status: DealStatusBought, FinalProfit: "2"},
+		{Id: 4, Status: "panic_sold", FinalProfit: "0.1"},
+		{Id: 5, Status: DealStatusCompleted},
+	}
+	require.Equal(t, []int{1, 4}, dealIDs(Filter(deals, DealFilterProfitable())))
+}
+
+func TestTotalOpenExposure(t *testing.T) {
+	deals := []Deal{
+		{Id: 1, Status: DealStatusBought, FromCurrency: "USDT", BoughtVolume: "100.5"},
+		{Id: 2, Status: DealStatusBought, FromCurrency: "usdt", BoughtVolume: "49.5"},
+		{Id: 3, Status: "base_order_placed", FromCurrency: "Btc", BoughtVolume: "0.0012"},
+		{Id: 4, Status: DealStatusCompleted, FromCurrency: "USDT", BoughtVolume: "500"}, // closed, skipped
+		{Id: 5, Status: "created", FromCurrency: "ETH"},                                 // nothing bought yet
+		{Id: 6, Status: "panic_sold", FromCurrency: "BTC", BoughtVolume: "1"},           // closed, skipped
+	}
+
+	exposure := TotalOpenExposure(deals)
+	require.Len(t, exposure, 2)
+	require.InDelta(t, 150.0, exposure["USDT"], 1e-9)
+	require.InDelta(t, 0.0012, exposure["BTC"], 1e-12)
+
+	require.Empty(t, TotalOpenExposure(nil))
+}
+
+func TestAggregateDealStats(t *testing.T) {
+	deals := []Deal{
+		{Id: 1, Status: DealStatusBought, FinalProfit: "10"}, // open, skipped
+		{Id: 2, Status: DealStatusCompleted, FinalProfit: "4.5"},
+		{Id: 3, Status: DealStatusCompleted, FinalProfit: "1.5"},
+		{Id: 4, Status: "stop_loss_finished", FinalProfit: "-3"},
+		{Id: 5, Status: DealStatusFailed, FinalProfit: "0"},
+		{Id: 6, Status: DealStatusCompleted}, // no profit, skipped
+	}
+
+	stats := AggregateDealStats(deals)
+	require.Equal(t, 4, stats.Count)
+	require.Equal(t, 2, stats.Completed)
+	require.Equal(t, 2, stats.Wins)
+	require.InDelta(t, 3.0, stats.TotalProfit, 1e-9)
+	require.InDelta(t, 0.75, stats.AverageProfit, 1e-9)
+	require.InDelta(t, 0.5, stats.WinRate, 1e-9)
+
+	require.Equal(t, DealStats{}, AggregateDealStats(nil))
+}
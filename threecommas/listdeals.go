@@ -0,0 +1,54 @@
+package threecommas
+
+import "fmt"
+
+// WithOrderingForListDeals sorts the deals listed by the server on field in
+// direction, e.g. ListDealsParamsOrderCreatedAt and
+// ListDealsParamsOrderDirectionASC for an incremental sync. Unknown values are
+// rejected by GetListOfDeals and GetDealsUpdatedSince.
+func WithOrderingForListDeals(field ListDealsParamsOrder, direction ListDealsParamsOrderDirection) ListDealsParamsOption {
+	return func(p *ListDealsParams) {
+		p.Order = &field
+		p.OrderDirection = &direction
+	}
+}
+
+// Valid reports whether o is a field the deals can be ordered on.
+func (o ListDealsParamsOrder) Valid() bool {
+	switch o {
+	case ListDealsParamsOrderClosedAt, ListDealsParamsOrderCreatedAt, ListDealsParamsOrderProfit,
+		ListDealsParamsOrderProfitPercentage, ListDealsParamsOrderUpdatedAt:
+		return true
+	}
+	return false
+}
+
+// Valid reports whether d is ASC or DESC.
+func (d ListDealsParamsOrderDirection) Valid() bool {
+	return d == ListDealsParamsOrderDirectionASC || d == ListDealsParamsOrderDirectionDESC
+}
+
+// Valid reports whether s is a scope the deals can be filtered on.
+func (s ListDealsParamsScope) Valid() bool {
+	switch s {
+	case ListDealsParamsScopeActive, ListDealsParamsScopeCancelled, ListDealsParamsScopeCompleted,
+		ListDealsParamsScopeFailed, ListDealsParamsScopeFinished:
+		return true
+	}
+	return false
+}
+
+// validateListDealsParams rejects unknown enum values before they are sent,
+// used by GetListOfDeals and GetDealsUpdatedSince.
+func validateListDealsParams(p *ListDealsParams) error {
+	if p.Scope != nil && !p.Scope.Valid() {
+		return fmt.Errorf("list deals: invalid scope %q", *p.Scope)
+	}
+	if p.Order != nil && !p.Order.Valid() {
+		return fmt.Errorf("list deals: invalid order %q", *p.Order)
+	}
+	if p.OrderDirection != nil && !p.OrderDirection.Valid() {
+		return fmt.Errorf("list deals: invalid order direction %q", *p.OrderDirection)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package threecommas
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var marketOrderStatuses = []MarketOrderStatusString{Active, Cancelled, Filled, Finished, Inactive}
+
+// ParseMarketOrderStatus parses s (case-insensitive) into a MarketOrderStatusString,
+// e.g. for building filters from config or CLI input.
+func ParseMarketOrderStatus(s string) (MarketOrderStatusString, error) {
+	for _, status := range marketOrderStatuses {
+		if strings.EqualFold(strings.TrimSpace(s), string(status)) {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("unknown market order status %q", s)
+}
+
+// Valid reports whether s is one of the known market order statuses.
+func (s MarketOrderStatusString) Valid() bool {
+	for _, status := range marketOrderStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// String implements fmt.Stringer.
+func (s MarketOrderStatusString) String() string {
+	return string(s)
+}
+
+// NetPosition returns the base quantity held by the deal according to orders,
+// e.g. from GetMarketOrdersForDeal: the filled buys minus the filled sells.
+// coin is the deal's base currency (ToCurrency). Orders that are not filled
+// or have an unparseable quantity are ignored.
+//
+// NetPosition is a Deal method rather than a function of the orders alone, as
+// MarketOrder has no currency field to take the coin from.
+func (d *Deal) NetPosition(orders []MarketOrder) (baseQty float64, coin string) {
+	coin = d.TradingPair().Base
+	for _, order := range orders {
+		if order.StatusString != Filled {
+			continue
+		}
+		qty, err := strconv.ParseFloat(order.Quantity, 64)
+		if err != nil {
+			continue
+		}
+		switch order.OrderType {
+		case BUY:
+			baseQty += qty
+		case SELL:
+			baseQty -= qty
+		}
+	}
+	return baseQty, coin
+}
@@ -0,0 +1,98 @@
+package threecommas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMarketOrderStatus(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    MarketOrderStatusString
+		wantErr string
+	}{
+		{input: "Filled", want: Filled},
+		{input: "filled", want: Filled},
+		{input: " ACTIVE ", want: Active},
+		{input: "Cancelled", want: Cancelled},
+		{input: "finished", want: Finished},
+		{input: "Inactive", want: Inactive},
+		{input: "Canceled", wantErr: `unknown market order status "Canceled"`},
+		{input: "", wantErr: `unknown market order status ""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMarketOrderStatus(tt.input)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.True(t, got.Valid())
+			require.Equal(t, string(tt.want), got.String())
+		})
+	}
+}
+
+func TestMarketOrderStatusValid(t *testing.T) {
+	require.True(t, Filled.Valid())
+	require.False(t, MarketOrderStatusString("filled").Valid())
+	require.False(t, MarketOrderStatusString("").Valid())
+}
+
+func TestDealNetPosition(t *testing.T) {
+	deal := &Deal{ToCurrency: "doge"}
+	order := func(side MarketOrderOrderType, status MarketOrderStatusString, qty string) MarketOrder {
+		return MarketOrder{OrderType: side, StatusString: status, Quantity: qty}
+	}
+
+	tests := []struct {
+		name   string
+		orders []MarketOrder
+		want   float64
+	}{
+		{name: "no orders"},
+		{
+			name: "filled buys",
+			orders: []MarketOrder{
+				order(BUY, Filled, "110.0"),
+				order(BUY, Filled, "107.0"),
+			},
+			want: 217,
+		},
+		{
+			name: "buys minus sells",
+			orders: []MarketOrder{
+				order(BUY, Filled, "110.0"),
+				order(BUY, Filled, "107.0"),
+				order(SELL, Filled, "100.0"),
+			},
+			want: 117,
+		},
+		{
+			name: "only filled orders count",
+			orders: []MarketOrder{
+				order(BUY, Filled, "110.0"),
+				order(BUY, Active, "107.0"),
+				order(SELL, Cancelled, "217.0"),
+				order(BUY, Filled, "not a number"),
+			},
+			want: 110,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qty, coin := deal.NetPosition(tt.orders)
+			require.InDelta(t, tt.want, qty, 1e-9)
+			require.Equal(t, "DOGE", coin)
+		})
+	}
+
+	qty, coin := (*Deal)(nil).NetPosition([]MarketOrder{order(BUY, Filled, "1")})
+	require.Equal(t, 1.0, qty)
+	require.Empty(t, coin)
+}
@@ -2,6 +2,8 @@ package threecommas
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"regexp"
 	"strconv"
@@ -25,35 +27,52 @@ const (
 // For example, with a 1-minute window, windows align to 12:30:00, 12:31:00, 12:32:00, etc.
 // This matches the 3commas API rate limiting behavior where limits reset at the start of each window.
 type fixedWindowLimiter struct {
+	clock       Clock
 	windowSize  time.Duration
 	limit       int
 	mu          sync.Mutex
 	windowStart time.Time
 	count       int
+	maxWait     time.Duration // fail with ErrRateLimitWaitExceeded beyond this, 0 waits indefinitely
 }
 
 func newFixedWindowLimiter(windowSize time.Duration, limit int) *fixedWindowLimiter {
 	return &fixedWindowLimiter{
+		clock:      realClock{},
 		windowSize: windowSize,
 		limit:      limit,
 	}
 }
 
+// advance resets the counter when now falls into a new window.
+// Must be called with l.mu held.
+func (l *fixedWindowLimiter) advance(now time.Time) {
+	// Align to window boundary (e.g., 12:30:37 -> 12:30:00 for 1-minute window)
+	currentWindowStart := now.Truncate(l.windowSize)
+
+	// If we've entered a new window, reset the counter
+	if currentWindowStart.After(l.windowStart) {
+		l.windowStart = currentWindowStart
+		l.count = 0
+	}
+}
+
+// Record counts a request against the current window without waiting,
+// even if that takes the window over its limit.
+func (l *fixedWindowLimiter) Record() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(l.clock.Now())
+	l.count++
+}
+
 // Wait blocks until the limiter allows the request or context is cancelled.
 // It uses clock-aligned windows that reset at fixed time boundaries.
 func (l *fixedWindowLimiter) Wait(ctx context.Context) error {
 	for {
 		l.mu.Lock()
-		now := time.Now()
-
-		// Align to window boundary (e.g., 12:30:37 -> 12:30:00 for 1-minute window)
-		currentWindowStart := now.Truncate(l.windowSize)
-
-		// If we've entered a new window, reset the counter
-		if currentWindowStart.After(l.windowStart) {
-			l.windowStart = currentWindowStart
-			l.count = 0
-		}
+		l.advance(l.clock.Now())
 
 		// Check if we can make a request in this window
 		if l.count < l.limit {
@@ -66,23 +85,44 @@ func (l *fixedWindowLimiter) Wait(ctx context.Context) error {
 		nextWindow := l.windowStart.Add(l.windowSize)
 		l.mu.Unlock()
 
-		waitDuration := time.Until(nextWindow)
+		waitDuration := nextWindow.Sub(l.clock.Now())
 		if waitDuration <= 0 {
 			// Window should have already passed, try again
 			continue
 		}
 
-		timer := time.NewTimer(waitDuration)
+		if l.maxWait > 0 && waitDuration > l.maxWait {
+			return fmt.Errorf("%w: next window opens in %s", ErrRateLimitWaitExceeded, waitDuration)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		timer := l.clock.NewTimer(waitDuration)
 		select {
 		case <-ctx.Done():
 			timer.Stop()
 			return ctx.Err()
-		case <-timer.C:
+		case <-timer.C():
 			// Window expired, try again
 		}
 	}
 }
 
+// scaleWindow shrinks the window to size while keeping the limit
+// proportional, e.g. 120 per minute becomes 2 per second.
+func (l *fixedWindowLimiter) scaleWindow(size time.Duration) error {
+	if size <= 0 || size > l.windowSize {
+		return fmt.Errorf("tier window %s must be positive and at most %s", size, l.windowSize)
+	}
+	limit := int(int64(l.limit) * int64(size) / int64(l.windowSize))
+	if limit < 1 {
+		return fmt.Errorf("tier window %s is too small for %d requests per %s", size, l.limit, l.windowSize)
+	}
+	l.windowSize = size
+	l.limit = limit
+	return nil
+}
+
 func tierLimiterForPlan(tier PlanTier) *fixedWindowLimiter {
 	switch tier {
 	case PlanStarter:
@@ -130,19 +170,220 @@ func threeCommasRoutes() []routeLimiter {
 	}
 }
 
+// RouteLimitInfo describes one limit enforced by the rate limiter.
+type RouteLimitInfo struct {
+	// Name is the route name, or tier_starter, tier_pro and tier_expert for
+	// the limit of each plan tier that applies to every request.
+	Name string
+	// Method and Pattern (a regular expression on the escaped URL path) select
+	// the requests of a route. Both are empty for the tier limits.
+	Method  string
+	Pattern string
+	Window  time.Duration
+	Limit   int
+}
+
+// KnownRouteLimits returns the limits the rate limiter enforces by default:
+// the route limits in matching order, followed by the tier limit of each plan.
+// WithTierWindow scales the tier limits of a client.
+func KnownRouteLimits() []RouteLimitInfo {
+	var infos []RouteLimitInfo
+	for _, r := range threeCommasRoutes() {
+		infos = append(infos, RouteLimitInfo{
+			Name:    r.name,
+			Method:  r.method,
+			Pattern: r.re.String(),
+			Window:  r.limiter.windowSize,
+			Limit:   r.limiter.limit,
+		})
+	}
+	for _, plan := range []struct {
+		name string
+		tier PlanTier
+	}{{"tier_starter", PlanStarter}, {"tier_pro", PlanPro}, {"tier_expert", PlanExpert}} {
+		l := tierLimiterForPlan(plan.tier)
+		infos = append(infos, RouteLimitInfo{Name: plan.name, Window: l.windowSize, Limit: l.limit})
+	}
+	return infos
+}
+
+// BlockBehavior controls what a request does while the tier or its route is
+// blocked after a 429 or 418 response.
+type BlockBehavior int
+
+const (
+	// BlockWait waits until the block expires (default).
+	BlockWait BlockBehavior = iota
+	// BlockFailFast returns a *RateLimitBlockedError immediately.
+	BlockFailFast
+)
+
+// WithBlockBehavior sets what requests do while the rate limiter is blocked.
+// Defaults to BlockWait, which after a 418 can mean waiting up to 10 minutes.
+func WithBlockBehavior(mode BlockBehavior) ThreeCommasClientOption {
+	return rateLimitOption(func(c *ThreeCommasClient) {
+		c.blockBehavior = mode
+	})
+}
+
+// ErrRateLimited is matched (via errors.Is) by the error returned for requests
+// refused because of an active rate limit block.
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrRateLimitWaitExceeded is returned when a request would wait longer than
+// allowed by WithMaxRateLimitWait. It also matches ErrRateLimited.
+var ErrRateLimitWaitExceeded = fmt.Errorf("%w: wait exceeds maximum", ErrRateLimited)
+
+// WithMaxRateLimitWait makes requests fail with ErrRateLimitWaitExceeded
+// instead of waiting when the rate limiter would hold them for longer than d,
+// either for the next window or for a block to expire. Zero, the default,
+// waits as long as needed.
+func WithMaxRateLimitWait(d time.Duration) ThreeCommasClientOption {
+	return rateLimitOption(func(c *ThreeCommasClient) {
+		c.maxLimitWait = d
+	})
+}
+
+// WithRateLimitBackoff sets how long the tier is blocked after a 429 without
+// a Retry-After header, on routes without a backoff of their own (see
+// KnownRouteLimits). It defaults to one window of the tier limit, e.g. a
+// minute, or the window set with WithTierWindow.
+func WithRateLimitBackoff(d time.Duration) ThreeCommasClientOption {
+	return rateLimitOption(func(c *ThreeCommasClient) {
+		c.limitBackoff = d
+	})
+}
+
+// RateLimitBlockedError is returned with BlockFailFast for a request refused
+// because of an active block.
+type RateLimitBlockedError struct {
+	// Key is "tier" or the name of the blocked route.
+	Key   string
+	Until time.Time
+}
+
+// Error implements the error interface.
+func (e *RateLimitBlockedError) Error() string {
+	return fmt.Sprintf("rate limited: %s blocked until %s", e.Key, e.Until.Format(time.RFC3339))
+}
+
+// Unwrap makes errors.Is(err, ErrRateLimited) hold.
+func (e *RateLimitBlockedError) Unwrap() error {
+	return ErrRateLimited
+}
+
 type rlEngine struct {
-	tier    *fixedWindowLimiter
-	routes  []routeLimiter
-	mu      sync.Mutex
-	blocked map[string]time.Time // key: "tier" or route.name -> blocked-until
+	clock         Clock
+	blockBehavior BlockBehavior
+	maxWait       time.Duration
+	defaultBlock  time.Duration // block after a 429 without Retry-After on routes without mitigation
+	tier          *fixedWindowLimiter
+	routes        []routeLimiter
+	mu            sync.Mutex
+	blocked       map[string]time.Time // key: "tier" or route.name -> blocked-until
 }
 
-func newRLEngine(tier PlanTier) *rlEngine {
-	return &rlEngine{
+func newRLEngine(tier PlanTier, clock Clock) *rlEngine {
+	e := &rlEngine{
+		clock:   clock,
 		tier:    tierLimiterForPlan(tier),
 		routes:  threeCommasRoutes(),
 		blocked: make(map[string]time.Time),
 	}
+	e.tier.clock = clock
+	e.defaultBlock = e.tier.windowSize
+	for i := range e.routes {
+		e.routes[i].limiter.clock = clock
+	}
+	return e
+}
+
+// newConfiguredRLEngine builds the engine for the rate limit settings of tc:
+// WithPlanTier, WithClock, WithBlockBehavior, WithMaxRateLimitWait,
+// WithTierWindow and WithRateLimitBackoff.
+func newConfiguredRLEngine(tc *ThreeCommasClient) (*rlEngine, error) {
+	if tc.maxLimitWait < 0 {
+		return nil, fmt.Errorf("max rate limit wait must not be negative")
+	}
+	if tc.limitBackoff < 0 {
+		return nil, fmt.Errorf("rate limit backoff must not be negative")
+	}
+	eng := newRLEngine(tc.planTier, tc.clock)
+	eng.blockBehavior = tc.blockBehavior
+	eng.setMaxWait(tc.maxLimitWait)
+	if tc.tierWindow != 0 {
+		if err := eng.tier.scaleWindow(tc.tierWindow); err != nil {
+			return nil, err
+		}
+	}
+	eng.defaultBlock = eng.tier.windowSize
+	if tc.limitBackoff > 0 {
+		eng.defaultBlock = tc.limitBackoff
+	}
+	return eng, nil
+}
+
+// RateLimiter holds rate limit state that can be shared by several clients
+// using the same API key, see WithSharedRateLimiter.
+type RateLimiter struct {
+	eng *rlEngine
+}
+
+// NewRateLimiter creates a RateLimiter configured by the rate limit options
+// WithPlanTier, WithTierWindow, WithBlockBehavior, WithMaxRateLimitWait,
+// WithRateLimitBackoff and WithClock. Any other option is rejected with an
+// error.
+func NewRateLimiter(opts ...ThreeCommasClientOption) (*RateLimiter, error) {
+	tc := &ThreeCommasClient{
+		planTier: PlanExpert,
+		clock:    realClock{},
+	}
+	for i, opt := range opts {
+		tc.rateLimitOpt = false
+		opt(tc)
+		if !tc.rateLimitOpt {
+			return nil, fmt.Errorf("option %d is not a rate limit option", i)
+		}
+	}
+	if tc.clock == nil {
+		tc.clock = realClock{}
+	}
+	eng, err := newConfiguredRLEngine(tc)
+	if err != nil {
+		return nil, err
+	}
+	return &RateLimiter{eng: eng}, nil
+}
+
+// rateLimitOption marks set as a rate limit option, the only kind of option
+// NewRateLimiter accepts.
+func rateLimitOption(set func(*ThreeCommasClient)) ThreeCommasClientOption {
+	return func(c *ThreeCommasClient) {
+		c.rateLimitOpt = true
+		set(c)
+	}
+}
+
+// WithSharedRateLimiter makes the client count its requests against limiter,
+// so that clients for the same account, e.g. in different goroutines or
+// modules, don't exceed the account limit together. Blocks after a 429 or 418
+// apply to all of them. The limiter's settings replace the rate limit options
+// of the client (WithPlanTier, WithTierWindow, WithBlockBehavior,
+// WithMaxRateLimitWait, WithRateLimitBackoff and WithClock).
+func WithSharedRateLimiter(limiter *RateLimiter) ThreeCommasClientOption {
+	return func(c *ThreeCommasClient) {
+		c.sharedLimiter = limiter
+	}
+}
+
+// setMaxWait applies the WithMaxRateLimitWait limit to the blocks and to
+// every limiter of the engine.
+func (e *rlEngine) setMaxWait(d time.Duration) {
+	e.maxWait = d
+	e.tier.maxWait = d
+	for i := range e.routes {
+		e.routes[i].limiter.maxWait = d
+	}
 }
 
 func (e *rlEngine) match(r *http.Request) *routeLimiter {
@@ -165,19 +406,31 @@ func (e *rlEngine) waitBlocked(ctx context.Context, key string) error {
 		if until.IsZero() {
 			return nil
 		}
-		d := time.Until(until)
+		d := until.Sub(e.clock.Now())
 		if d <= 0 {
 			e.mu.Lock()
-			delete(e.blocked, key)
+			// Another request may have extended the block in the meantime
+			if e.blocked[key].Equal(until) {
+				delete(e.blocked, key)
+			}
 			e.mu.Unlock()
-			return nil
+			continue
 		}
-		t := time.NewTimer(d)
+		if e.blockBehavior == BlockFailFast {
+			return &RateLimitBlockedError{Key: key, Until: until}
+		}
+		if e.maxWait > 0 && d > e.maxWait {
+			return fmt.Errorf("%w: %s blocked for %s", ErrRateLimitWaitExceeded, key, d)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		t := e.clock.NewTimer(d)
 		select {
 		case <-ctx.Done():
 			t.Stop()
 			return ctx.Err()
-		case <-t.C:
+		case <-t.C():
 		}
 	}
 }
@@ -186,7 +439,7 @@ func (e *rlEngine) backoff(key string, d time.Duration) {
 	if d <= 0 {
 		return
 	}
-	deadline := time.Now().Add(d)
+	deadline := e.clock.Now().Add(d)
 	e.mu.Lock()
 	if cur, ok := e.blocked[key]; !ok || deadline.After(cur) {
 		e.blocked[key] = deadline
@@ -194,9 +447,38 @@ func (e *rlEngine) backoff(key string, d time.Duration) {
 	e.mu.Unlock()
 }
 
+// WithTierWindow splits the plan's per-minute budget into smaller windows to
+// smooth bursts, e.g. PlanExpert with a 1s window allows 2 requests per second.
+// New3CommasClient fails if the derived limit is below 1 request per window.
+func WithTierWindow(windowSize time.Duration) ThreeCommasClientOption {
+	return rateLimitOption(func(c *ThreeCommasClient) {
+		c.tierWindow = windowSize
+	})
+}
+
+type rateLimitPriorityKey struct{}
+
+// WithRateLimitPriority returns a context whose requests skip waiting on the
+// tier and route limiters. The requests are still counted, so subsequent
+// requests account for them, and active backoff blocks (after a 429 or 418)
+// are still honoured.
+//
+// Use this judiciously, e.g. for an urgent panic sell: skipping the limiter
+// makes it possible to exceed the account limit and receive a 429, which in
+// turn blocks all requests for the backoff period.
+func WithRateLimitPriority(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rateLimitPriorityKey{}, true)
+}
+
+func hasRateLimitPriority(ctx context.Context) bool {
+	priority, _ := ctx.Value(rateLimitPriorityKey{}).(bool)
+	return priority
+}
+
 type rateLimitDoer struct {
-	base HttpRequestDoer
-	eng  *rlEngine
+	base         HttpRequestDoer
+	eng          *rlEngine
+	interceptors []ResponseInterceptorFn
 }
 
 func (d *rateLimitDoer) Do(req *http.Request) (*http.Response, error) {
@@ -210,21 +492,37 @@ func (d *rateLimitDoer) Do(req *http.Request) (*http.Response, error) {
 		}
 	}
 
-	// Wait on TIER limiter first (subscription plan limit)
-	if err := d.eng.tier.Wait(req.Context()); err != nil {
-		return nil, err
-	}
-	// Wait on ROUTE limiter if matched (additional per-endpoint limit)
-	if matched := d.eng.match(req); matched != nil {
-		if err := matched.limiter.Wait(req.Context()); err != nil {
+	if hasRateLimitPriority(req.Context()) {
+		// Priority requests skip the windows but still count against them
+		d.eng.tier.Record()
+		if matched := d.eng.match(req); matched != nil {
+			matched.limiter.Record()
+		}
+	} else {
+		// Wait on TIER limiter first (subscription plan limit)
+		if err := d.eng.tier.Wait(req.Context()); err != nil {
 			return nil, err
 		}
+		// Wait on ROUTE limiter if matched (additional per-endpoint limit)
+		if matched := d.eng.match(req); matched != nil {
+			if err := matched.limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Send
 	resp, err := d.base.Do(req)
 	if err != nil {
-		return resp, err
+		return resp, newNetworkError(req, err)
+	}
+
+	// Let interceptors inspect or rewrite the response before we react to it
+	for _, intercept := range d.interceptors {
+		if err := intercept(resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
 	}
 
 	// Observe and react
@@ -233,11 +531,11 @@ func (d *rateLimitDoer) Do(req *http.Request) (*http.Response, error) {
 		// Getting a 429 means our rate limiting failed to prevent it.
 		// Since the TIER limit is the primary constraint for most users,
 		// we should block the TIER limiter (not just the route).
-		block := 5 * time.Minute // default per docs
+		block := d.eng.defaultBlock
 		if matched := d.eng.match(req); matched != nil {
 			block = matched.mitigation
 		}
-		if ra := parseRetryAfter(resp.Header.Get("Retry-After")); ra > 0 {
+		if ra := parseRetryAfter(resp.Header.Get("Retry-After"), d.eng.clock.Now()); ra > 0 {
 			block = ra // prefer server hint
 		}
 		// Always block TIER limiter since it's the account-wide limit
@@ -254,7 +552,7 @@ func (d *rateLimitDoer) Do(req *http.Request) (*http.Response, error) {
 	return resp, nil
 }
 
-func parseRetryAfter(v string) time.Duration {
+func parseRetryAfter(v string, now time.Time) time.Duration {
 	if v == "" {
 		return 0
 	}
@@ -262,7 +560,7 @@ func parseRetryAfter(v string) time.Duration {
 		return time.Duration(secs) * time.Second
 	}
 	if when, err := http.ParseTime(v); err == nil {
-		if d := time.Until(when); d > 0 {
+		if d := when.Sub(now); d > 0 {
 			return d
 		}
 	}
@@ -277,7 +575,15 @@ func WithThreeCommasRateLimits(tier ...PlanTier) ClientOption {
 		if len(tier) > 0 {
 			t = tier[0]
 		}
+		// Each client gets its own engine, WithSharedRateLimiter shares one
+		return withRateLimitDoer(newRLEngine(t, realClock{}), nil)(c)
+	}
+}
 
+// withRateLimitDoer wraps the current Doer of the client with a rateLimitDoer
+// driven by the given engine.
+func withRateLimitDoer(eng *rlEngine, interceptors []ResponseInterceptorFn) ClientOption {
+	return func(c *Client) error {
 		// IMPORTANT: if c.Client is nil here, NewClient will NOT assign a default later
 		// once we set c.Client to our wrapper. So make sure the wrapper has a non-nil base.
 		base := c.Client
@@ -285,8 +591,9 @@ func WithThreeCommasRateLimits(tier ...PlanTier) ClientOption {
 			base = &http.Client{}
 		}
 		c.Client = &rateLimitDoer{
-			base: base,
-			eng:  newRLEngine(t),
+			base:         base,
+			eng:          eng,
+			interceptors: interceptors,
 		}
 		return nil
 	}
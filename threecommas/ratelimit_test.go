@@ -2,8 +2,11 @@ package threecommas
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -153,3 +156,551 @@ func TestTierLimiterForPlan(t *testing.T) {
 		})
 	}
 }
+
+func TestKnownRouteLimits(t *testing.T) {
+	require.Equal(t, []RouteLimitInfo{
+		{Name: "deals_list", Method: http.MethodGet, Pattern: `^/ver1/deals$`, Window: time.Minute, Limit: 120},
+		{Name: "deal_show", Method: http.MethodGet, Pattern: `^/ver1/deals/\d+/show$`, Window: time.Minute, Limit: 120},
+		{Name: "smart_trades", Method: http.MethodGet, Pattern: `^/ver1/smart_trades(?:/|$)`, Window: 10 * time.Second, Limit: 40},
+		{Name: "tier_starter", Window: time.Minute, Limit: 5},
+		{Name: "tier_pro", Window: time.Minute, Limit: 50},
+		{Name: "tier_expert", Window: time.Minute, Limit: 120},
+	}, KnownRouteLimits())
+}
+
+func TestResponseInterceptorForces429(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 123}`))
+	}))
+	defer server.Close()
+
+	var intercepted atomic.Int32
+	client, err := New3CommasClient(
+		WithAPIKey("test-key"),
+		WithPrivatePEM([]byte(fakeKey)),
+		WithThreeCommasBaseURL(server.URL),
+		WithResponseInterceptor(func(resp *http.Response) error {
+			intercepted.Add(1)
+			resp.StatusCode = http.StatusTooManyRequests
+			resp.Header.Set("Retry-After", "30")
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	resp, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode())
+	require.Equal(t, int32(1), intercepted.Load())
+
+	// The injected 429 must have blocked the tier and the matched route
+	client.rateLimits.mu.Lock()
+	tierUntil := client.rateLimits.blocked["tier"]
+	routeUntil := client.rateLimits.blocked["deal_show"]
+	client.rateLimits.mu.Unlock()
+
+	require.WithinDuration(t, time.Now().Add(30*time.Second), tierUntil, 2*time.Second)
+	require.WithinDuration(t, time.Now().Add(30*time.Second), routeUntil, 2*time.Second)
+}
+
+func TestResponseInterceptorError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	wantErr := errors.New("injected failure")
+	client, err := New3CommasClient(
+		WithAPIKey("test-key"),
+		WithPrivatePEM([]byte(fakeKey)),
+		WithThreeCommasBaseURL(server.URL),
+		WithResponseInterceptor(func(resp *http.Response) error {
+			return wantErr
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetDealWithResponse(context.Background(), DealPathId(123))
+	require.ErrorIs(t, err, wantErr)
+}
+
+func TestWithRateLimitPriority(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// A single request per hour makes the window exhaustion deterministic
+	eng := newRLEngine(PlanExpert, realClock{})
+	eng.tier = newFixedWindowLimiter(time.Hour, 1)
+	doer := &rateLimitDoer{base: http.DefaultClient, eng: eng}
+
+	do := func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/ver1/bots", nil)
+		require.NoError(t, err)
+		resp, err := doer.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	// First request consumes the whole window
+	require.NoError(t, do(context.Background()))
+
+	// A priority request does not wait, but is still counted
+	ctx, cancel := context.WithTimeout(WithRateLimitPriority(context.Background()), 100*time.Millisecond)
+	defer cancel()
+	require.NoError(t, do(ctx))
+	require.Equal(t, 2, eng.tier.count)
+
+	// A regular request is still held back by the exhausted window
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, do(ctx), context.DeadlineExceeded)
+}
+
+func TestWithBlockBehavior(t *testing.T) {
+	newClient := func(t *testing.T, mode BlockBehavior) (*ThreeCommasClient, *fakeClock, *atomic.Int32) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.WriteHeader(http.StatusTeapot)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+
+		clock := newFakeClock(time.Date(2025, 8, 4, 12, 30, 10, 0, time.UTC))
+		client, err := New3CommasClient(
+			WithAPIKey("test-key"),
+			WithPrivatePEM([]byte(fakeKey)),
+			WithThreeCommasBaseURL(server.URL),
+			WithClock(clock),
+			WithBlockBehavior(mode),
+		)
+		require.NoError(t, err)
+
+		// The 418 itself is returned to the caller and blocks the tier
+		resp, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusTeapot, resp.StatusCode())
+		return client, clock, &requests
+	}
+
+	t.Run("wait", func(t *testing.T) {
+		client, clock, requests := newClient(t, BlockWait)
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+			done <- err
+		}()
+
+		clock.WaitForTimers(t, 1)
+		clock.Advance(10 * time.Minute)
+		require.NoError(t, <-done)
+		require.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		client, clock, requests := newClient(t, BlockFailFast)
+
+		_, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+		require.ErrorIs(t, err, ErrRateLimited)
+
+		var blocked *RateLimitBlockedError
+		require.ErrorAs(t, err, &blocked)
+		require.Equal(t, "tier", blocked.Key)
+		require.Equal(t, clock.Now().Add(10*time.Minute), blocked.Until)
+		require.Equal(t, int32(1), requests.Load())
+
+		// Once the block expired requests go through again
+		clock.Advance(10 * time.Minute)
+		_, err = client.GetDealWithResponse(context.Background(), DealPathId(123))
+		require.NoError(t, err)
+		require.Equal(t, int32(2), requests.Load())
+	})
+}
+
+func TestRateLimitBackoffWithoutRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name string
+		opts []ThreeCommasClientOption
+		want time.Duration
+	}{
+		{name: "starter", opts: []ThreeCommasClientOption{WithPlanTier(PlanStarter)}, want: time.Minute},
+		{name: "pro", opts: []ThreeCommasClientOption{WithPlanTier(PlanPro)}, want: time.Minute},
+		{name: "expert", opts: []ThreeCommasClientOption{WithPlanTier(PlanExpert)}, want: time.Minute},
+		{name: "scaled window", opts: []ThreeCommasClientOption{WithPlanTier(PlanExpert), WithTierWindow(10 * time.Second)}, want: 10 * time.Second},
+		{name: "configured", opts: []ThreeCommasClientOption{WithPlanTier(PlanStarter), WithRateLimitBackoff(5 * time.Minute)}, want: 5 * time.Minute},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := newFakeClock(time.Date(2025, 8, 4, 12, 30, 10, 0, time.UTC))
+			client, err := New3CommasClient(append([]ThreeCommasClientOption{
+				WithAPIKey("test-key"),
+				WithPrivatePEM([]byte(fakeKey)),
+				WithThreeCommasBaseURL(server.URL),
+				WithClock(clock),
+			}, tc.opts...)...)
+			require.NoError(t, err)
+
+			// The bots list has no route limit, so the default applies
+			resp, err := client.ListBotsWithResponse(context.Background(), ListBotsParamsFromOptions())
+			require.NoError(t, err)
+			require.Equal(t, http.StatusTooManyRequests, resp.StatusCode())
+
+			client.rateLimits.mu.Lock()
+			tierUntil := client.rateLimits.blocked["tier"]
+			client.rateLimits.mu.Unlock()
+			require.Equal(t, clock.Now().Add(tc.want), tierUntil)
+
+			// Routes keep their own mitigation
+			clock.Advance(tc.want)
+			resp2, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+			require.NoError(t, err)
+			require.Equal(t, http.StatusTooManyRequests, resp2.StatusCode())
+			client.rateLimits.mu.Lock()
+			routeUntil := client.rateLimits.blocked["deal_show"]
+			client.rateLimits.mu.Unlock()
+			require.Equal(t, clock.Now().Add(60*time.Second), routeUntil)
+		})
+	}
+
+	_, err := New3CommasClient(append(defaultTestOptions(), WithRateLimitBackoff(-time.Second))...)
+	require.Error(t, err)
+}
+
+func TestWithMaxRateLimitWait(t *testing.T) {
+	newClient := func(t *testing.T, maxWait time.Duration, status int) (*ThreeCommasClient, *fakeClock, *atomic.Int32) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.WriteHeader(status)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+
+		clock := newFakeClock(time.Date(2025, 8, 4, 12, 30, 10, 0, time.UTC))
+		client, err := New3CommasClient(
+			WithAPIKey("test-key"),
+			WithPrivatePEM([]byte(fakeKey)),
+			WithThreeCommasBaseURL(server.URL),
+			WithClock(clock),
+			WithPlanTier(PlanStarter),
+			WithMaxRateLimitWait(maxWait),
+		)
+		require.NoError(t, err)
+		return client, clock, &requests
+	}
+
+	t.Run("block exceeds max wait", func(t *testing.T) {
+		client, _, requests := newClient(t, time.Minute, http.StatusTeapot)
+
+		// The 418 blocks the tier for 10 minutes
+		_, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+		require.NoError(t, err)
+
+		_, err = client.GetDealWithResponse(context.Background(), DealPathId(123))
+		require.ErrorIs(t, err, ErrRateLimitWaitExceeded)
+		require.ErrorIs(t, err, ErrRateLimited)
+		require.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("block within max wait", func(t *testing.T) {
+		client, clock, requests := newClient(t, 15*time.Minute, http.StatusTeapot)
+
+		_, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+		require.NoError(t, err)
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+			done <- err
+		}()
+
+		clock.WaitForTimers(t, 1)
+		clock.Advance(10 * time.Minute)
+		require.NoError(t, <-done)
+		require.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("window exceeds max wait", func(t *testing.T) {
+		client, _, requests := newClient(t, 10*time.Second, http.StatusOK)
+
+		// Use up the Starter window, the next one opens in 50s
+		for range 5 {
+			_, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+			require.NoError(t, err)
+		}
+
+		_, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+		require.ErrorIs(t, err, ErrRateLimitWaitExceeded)
+		require.Equal(t, int32(5), requests.Load())
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		_, err := New3CommasClient(append(defaultTestOptions(), WithMaxRateLimitWait(-time.Second))...)
+		require.Error(t, err)
+	})
+}
+
+func TestWithSharedRateLimiter(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2025, 8, 4, 12, 30, 10, 0, time.UTC))
+	limiter, err := NewRateLimiter(
+		WithPlanTier(PlanStarter),
+		WithClock(clock),
+		WithMaxRateLimitWait(10*time.Second),
+	)
+	require.NoError(t, err)
+
+	newClient := func() *ThreeCommasClient {
+		client, err := New3CommasClient(
+			WithAPIKey("test-key"),
+			WithPrivatePEM([]byte(fakeKey)),
+			WithThreeCommasBaseURL(server.URL),
+			WithClock(clock),
+			WithPlanTier(PlanExpert), // replaced by the limiter's tier
+			WithSharedRateLimiter(limiter),
+		)
+		require.NoError(t, err)
+		return client
+	}
+	first, second := newClient(), newClient()
+
+	// Together the clients use up the Starter window of 5 requests
+	for i := range 5 {
+		client := first
+		if i%2 == 1 {
+			client = second
+		}
+		_, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+		require.NoError(t, err)
+	}
+
+	for _, client := range []*ThreeCommasClient{first, second} {
+		_, err := client.GetDealWithResponse(context.Background(), DealPathId(123))
+		require.ErrorIs(t, err, ErrRateLimitWaitExceeded)
+	}
+	require.Equal(t, int32(5), requests.Load())
+
+	// A client with its own limiter is unaffected
+	own, err := New3CommasClient(
+		WithAPIKey("test-key"),
+		WithPrivatePEM([]byte(fakeKey)),
+		WithThreeCommasBaseURL(server.URL),
+		WithClock(clock),
+	)
+	require.NoError(t, err)
+	_, err = own.GetDealWithResponse(context.Background(), DealPathId(123))
+	require.NoError(t, err)
+	require.Equal(t, int32(6), requests.Load())
+}
+
+func TestWithThreeCommasRateLimitsReused(t *testing.T) {
+	opt := WithThreeCommasRateLimits(PlanStarter)
+	engine := func() *rlEngine {
+		c, err := NewClient("https://example.com", opt)
+		require.NoError(t, err)
+		doer, ok := c.Client.(*rateLimitDoer)
+		require.True(t, ok)
+		return doer.eng
+	}
+
+	// Reusing the option must not share a limiter between the clients
+	require.NotSame(t, engine(), engine())
+}
+
+func TestNewRateLimiterInvalid(t *testing.T) {
+	_, err := NewRateLimiter(WithMaxRateLimitWait(-time.Second))
+	require.Error(t, err)
+	_, err = NewRateLimiter(WithPlanTier(PlanStarter), WithTierWindow(time.Second))
+	require.Error(t, err)
+	_, err = NewRateLimiter(WithPlanTier(PlanStarter), WithAPIKey("test-key"))
+	require.ErrorContains(t, err, "option 1 is not a rate limit option")
+	_, err = NewRateLimiter(WithRetry(3, time.Second))
+	require.Error(t, err)
+}
+
+func TestWithTierWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		tier       PlanTier
+		window     time.Duration
+		wantLimit  int
+		wantErrMsg string
+	}{
+		{name: "expert per second", tier: PlanExpert, window: time.Second, wantLimit: 2},
+		{name: "pro per 6 seconds", tier: PlanPro, window: 6 * time.Second, wantLimit: 5},
+		{name: "starter per 12 seconds", tier: PlanStarter, window: 12 * time.Second, wantLimit: 1},
+		{name: "starter per second", tier: PlanStarter, window: time.Second, wantErrMsg: "tier window 1s is too small for 5 requests per 1m0s"},
+		{name: "longer than a minute", tier: PlanExpert, window: 2 * time.Minute, wantErrMsg: "tier window 2m0s must be positive and at most 1m0s"},
+		{name: "negative", tier: PlanExpert, window: -time.Second, wantErrMsg: "tier window -1s must be positive and at most 1m0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New3CommasClient(
+				WithAPIKey("test-key"),
+				WithPrivatePEM([]byte(fakeKey)),
+				WithPlanTier(tt.tier),
+				WithTierWindow(tt.window),
+			)
+			if tt.wantErrMsg != "" {
+				require.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.window, client.rateLimits.tier.windowSize)
+			require.Equal(t, tt.wantLimit, client.rateLimits.tier.limit)
+		})
+	}
+}
+
+func TestWithTierWindowPacing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2025, 8, 4, 12, 30, 10, 0, time.UTC))
+	client, err := New3CommasClient(
+		WithAPIKey("test-key"),
+		WithPrivatePEM([]byte(fakeKey)),
+		WithThreeCommasBaseURL(server.URL),
+		WithClock(clock),
+		WithTierWindow(time.Second),
+	)
+	require.NoError(t, err)
+
+	// PlanExpert in 1s windows: two requests go through right away
+	for range 2 {
+		_, err := client.ListBotsWithResponse(context.Background(), nil)
+		require.NoError(t, err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.ListBotsWithResponse(context.Background(), nil)
+		done <- err
+	}()
+
+	// The third waits for the next second, not the next minute
+	clock.WaitForTimers(t, 1)
+	select {
+	case <-done:
+		t.Fatal("third request went through within the same window")
+	default:
+	}
+
+	clock.Advance(time.Second)
+	require.NoError(t, <-done)
+}
+
+func TestRateLimitCancelNoLeak(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	exhausted := newFixedWindowLimiter(time.Hour, 1)
+	exhausted.Record()
+
+	blocked := newRLEngine(PlanExpert, realClock{})
+	blocked.backoff("tier", time.Hour)
+
+	full := newRLEngine(PlanExpert, realClock{})
+	full.tier = exhausted
+
+	waits := map[string]func(ctx context.Context) error{
+		"Wait": exhausted.Wait,
+		"waitBlocked": func(ctx context.Context) error {
+			return blocked.waitBlocked(ctx, "tier")
+		},
+		"Do": func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/ver1/bots", nil)
+			if err != nil {
+				return err
+			}
+			resp, err := (&rateLimitDoer{base: http.DefaultClient, eng: full}).Do(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+			return err
+		},
+	}
+
+	for name, wait := range waits {
+		t.Run(name, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+
+			const rounds, parallel = 50, 100
+			for range rounds {
+				var wg sync.WaitGroup
+				errs := make(chan error, parallel)
+				cancels := make([]context.CancelFunc, parallel)
+				for i := range parallel {
+					ctx, cancel := context.WithCancel(context.Background())
+					cancels[i] = cancel
+					// Cancel half before the waiter starts, the rest mid-wait
+					if i%2 == 0 {
+						cancel()
+					}
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						errs <- wait(ctx)
+					}()
+				}
+				runtime.Gosched()
+				for _, cancel := range cancels {
+					cancel()
+				}
+				wg.Wait()
+				close(errs)
+				for err := range errs {
+					require.ErrorIs(t, err, context.Canceled)
+				}
+			}
+
+			requireGoroutinesSettle(t, before)
+		})
+	}
+}
+
+// requireGoroutinesSettle fails when the number of goroutines doesn't drop
+// back to before, allowing for runtime and HTTP keep-alive goroutines.
+func requireGoroutinesSettle(t *testing.T, before int) {
+	t.Helper()
+	const slack = 4
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		now := runtime.NumGoroutine()
+		if now <= before+slack {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines leaked: %d before, %d after", before, now)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
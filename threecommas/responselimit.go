@@ -0,0 +1,88 @@
+package threecommas
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// defaultMaxResponseBytes is the response body limit used unless
+// WithMaxResponseBytes sets another one. The largest responses, full deal
+// lists, stay well below it.
+const defaultMaxResponseBytes = 32 << 20
+
+// ErrResponseTooLarge is matched (via errors.Is) by errors caused by response
+// bodies over the limit set with WithMaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// WithMaxResponseBytes limits response bodies to n bytes, 32 MiB by default.
+// Reading past the limit fails with an error matching ErrResponseTooLarge,
+// which the request returns. n must be positive.
+func WithMaxResponseBytes(n int64) ThreeCommasClientOption {
+	return func(c *ThreeCommasClient) {
+		c.maxBodyBytes = n
+	}
+}
+
+type limitBodyDoer struct {
+	base HttpRequestDoer
+	max  int64
+}
+
+func (d *limitBodyDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.base.Do(req)
+	if err != nil || resp == nil || resp.Body == nil {
+		return resp, err
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, max: d.max, remaining: d.max}
+	return resp, nil
+}
+
+// limitedBody reads up to max bytes from the body, like http.MaxBytesReader
+// does for request bodies.
+type limitedBody struct {
+	io.ReadCloser
+	max       int64
+	remaining int64
+	err       error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// Read one byte past the limit to tell a body of exactly max bytes
+	// from a larger one
+	if int64(len(p))-1 > b.remaining {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) <= b.remaining {
+		b.remaining -= int64(n)
+		b.err = err
+		return n, err
+	}
+	n = int(b.remaining)
+	b.remaining = 0
+	b.err = fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, b.max)
+	return n, b.err
+}
+
+// withLimitBodyDoer wraps the current Doer of the client with a
+// limitBodyDoer. The Doers wrap each other in the order HTTP client →
+// errorBodyDoer → limitBodyDoer → trace → rate limiter, so the tracer, the
+// rate limiter and the response interceptors all read the limited body.
+func withLimitBodyDoer(max int64) ClientOption {
+	return func(c *Client) error {
+		base := c.Client
+		if base == nil {
+			base = &http.Client{}
+		}
+		c.Client = &limitBodyDoer{base: base, max: max}
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+package threecommas
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	body := `{"id": 123, "pair": "USDT_DOGE"}`
+
+	cases := []struct {
+		name    string
+		max     int64
+		wantErr bool
+	}{
+		{name: "default"},
+		{name: "exactly at the limit", max: int64(len(body))},
+		{name: "over the limit", max: int64(len(body)) - 1, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append(defaultTestOptions(), withHTTPClient(doerFunc(func(*http.Request) (*http.Response, error) {
+				return okResponse(body), nil
+			})))
+			if tc.max > 0 {
+				opts = append(opts, WithMaxResponseBytes(tc.max))
+			}
+			client, err := New3CommasClient(opts...)
+			require.NoError(t, err)
+
+			deal, err := client.GetDealForID(context.Background(), 123)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrResponseTooLarge)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 123, deal.Id)
+		})
+	}
+}
+
+func TestWithMaxResponseBytesOversized(t *testing.T) {
+	// A body far over the limit is cut off instead of read into memory
+	oversized := `{"id": 123, "note": "` + strings.Repeat("x", 1<<20) + `"}`
+	client, err := New3CommasClient(append(defaultTestOptions(),
+		withHTTPClient(doerFunc(func(*http.Request) (*http.Response, error) {
+			return okResponse(oversized), nil
+		})),
+		WithMaxResponseBytes(1024),
+	)...)
+	require.NoError(t, err)
+
+	_, err = client.GetDealForID(context.Background(), 123)
+	require.ErrorIs(t, err, ErrResponseTooLarge)
+}
+
+func TestWithMaxResponseBytesInvalid(t *testing.T) {
+	_, err := New3CommasClient(append(defaultTestOptions(), WithMaxResponseBytes(0))...)
+	require.Error(t, err)
+	_, err = New3CommasClient(append(defaultTestOptions(), WithMaxResponseBytes(-1))...)
+	require.Error(t, err)
+}
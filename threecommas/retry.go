@@ -0,0 +1,205 @@
+package threecommas
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+)
+
+// NetworkError wraps a failure of the underlying HTTP client to get a response,
+// e.g. a DNS failure, a refused connection, a timeout or a TLS error. It is
+// returned wrapped, use errors.As to get it.
+type NetworkError struct {
+	Method string
+	URL    string
+	Err    error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("network error: %s %s: %v", e.Method, e.URL, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// Timeout reports whether the request timed out.
+func (e *NetworkError) Timeout() bool { return IsTimeout(e.Err) }
+
+// Temporary reports whether the request may succeed when retried.
+func (e *NetworkError) Temporary() bool { return IsTemporary(e.Err) }
+
+func newNetworkError(req *http.Request, err error) error {
+	return &NetworkError{Method: req.Method, URL: req.URL.Redacted(), Err: err}
+}
+
+// IsTimeout reports whether err is caused by a timeout, either of the network
+// or of a context deadline.
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsTemporary reports whether err is a network failure that may succeed when
+// the request is retried: timeouts, refused or reset connections and temporary
+// DNS failures. Cancelled contexts, unknown hosts and TLS errors are not.
+func IsTemporary(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &certErr) || errors.As(err, &unknownAuthority) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &recordErr) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+
+	if IsTimeout(err) {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// WithRetry retries idempotent requests (GET, HEAD, OPTIONS) up to maxRetries
+// times when they fail with a temporary network error (see IsTemporary). The
+// wait before retry n is backoff * 2^(n-1). Every attempt passes the rate
+// limiter again. Responses are never retried, including 429s.
+func WithRetry(maxRetries int, backoff time.Duration) ThreeCommasClientOption {
+	return func(c *ThreeCommasClient) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// WithTimeoutPerAttempt gives every attempt of a request, see WithRetry, its
+// own deadline of d. An attempt that times out is retried like any other
+// timeout, while the request's context still bounds all attempts together.
+// The deadline covers the attempt's wait in the rate limiter.
+func WithTimeoutPerAttempt(d time.Duration) ThreeCommasClientOption {
+	return func(c *ThreeCommasClient) {
+		c.attemptTimeout = d
+	}
+}
+
+type retryDoer struct {
+	base       HttpRequestDoer
+	clock      Clock
+	maxRetries int
+	backoff    time.Duration
+	timeout    time.Duration
+}
+
+func (d *retryDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.attempt(req)
+	if !retryable(req) {
+		return resp, err
+	}
+
+	for attempt := 0; attempt < d.maxRetries && err != nil && IsTemporary(err); attempt++ {
+		if waitErr := d.wait(req.Context(), d.backoff<<attempt); waitErr != nil {
+			return nil, errors.Join(err, waitErr)
+		}
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, errors.Join(err, bodyErr)
+			}
+			req.Body = body
+		}
+		resp, err = d.attempt(req)
+	}
+	return resp, err
+}
+
+// attempt sends req once, under the per-attempt timeout if one is set. The
+// attempt's context is released when the response body is closed.
+func (d *retryDoer) attempt(req *http.Request) (*http.Response, error) {
+	if d.timeout <= 0 {
+		return d.base.Do(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), d.timeout)
+	resp, err := d.base.Do(req.WithContext(ctx))
+	if err != nil || resp == nil || resp.Body == nil {
+		cancel()
+		return resp, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func (d *retryDoer) wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := d.clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
+
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	}
+	return false
+}
+
+// withRetryDoer wraps the current Doer of the client with a retryDoer. It is
+// installed after the rate limiter so every attempt is rate limited.
+func withRetryDoer(clock Clock, maxRetries int, backoff, timeout time.Duration) ClientOption {
+	return func(c *Client) error {
+		base := c.Client
+		if base == nil {
+			base = &http.Client{}
+		}
+		c.Client = &retryDoer{
+			base:       base,
+			clock:      clock,
+			maxRetries: maxRetries,
+			backoff:    backoff,
+			timeout:    timeout,
+		}
+		return nil
+	}
+}
@@ -0,0 +1,310 @@
+package threecommas
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+func okResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestNetworkErrorClassification(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		timeout   bool
+		temporary bool
+	}{
+		{
+			name:      "connection refused",
+			err:       &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			temporary: true,
+		},
+		{
+			name:      "connection reset",
+			err:       &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+			temporary: true,
+		},
+		{
+			name:      "dial timeout",
+			err:       &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded},
+			timeout:   true,
+			temporary: true,
+		},
+		{
+			name:      "context deadline",
+			err:       fmt.Errorf("Get %q: %w", "https://api.3commas.io", context.DeadlineExceeded),
+			timeout:   true,
+			temporary: true,
+		},
+		{
+			name:      "dns timeout",
+			err:       &net.DNSError{Err: "i/o timeout", Name: "api.3commas.io", IsTimeout: true},
+			timeout:   true,
+			temporary: true,
+		},
+		{
+			name:      "dns temporary",
+			err:       &net.DNSError{Err: "server misbehaving", Name: "api.3commas.io", IsTemporary: true},
+			temporary: true,
+		},
+		{
+			name: "dns not found",
+			err:  &net.DNSError{Err: "no such host", Name: "api.3commas.invalid", IsNotFound: true},
+		},
+		{
+			name: "tls unknown authority",
+			err:  x509.UnknownAuthorityError{},
+		},
+		{
+			name: "context canceled",
+			err:  context.Canceled,
+		},
+		{
+			name: "other",
+			err:  errors.New("boom"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "https://api.3commas.io/public/api/ver1/deals", nil)
+			err := fmt.Errorf("request failed: %w", newNetworkError(req, tc.err))
+
+			require.Equal(t, tc.timeout, IsTimeout(err))
+			require.Equal(t, tc.temporary, IsTemporary(err))
+
+			var netErr *NetworkError
+			require.ErrorAs(t, err, &netErr)
+			require.Equal(t, tc.timeout, netErr.Timeout())
+			require.Equal(t, tc.temporary, netErr.Temporary())
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	require.False(t, IsTimeout(nil))
+	require.False(t, IsTemporary(nil))
+}
+
+func TestNetworkErrorFromClient(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	client, err := New3CommasClient(append(defaultTestOptions(),
+		withHTTPClient(doerFunc(func(*http.Request) (*http.Response, error) {
+			return nil, refused
+		})),
+	)...)
+	require.NoError(t, err)
+
+	_, err = client.GetDealForID(context.Background(), 123)
+	var netErr *NetworkError
+	require.ErrorAs(t, err, &netErr)
+	require.Equal(t, http.MethodGet, netErr.Method)
+	require.True(t, IsTemporary(err))
+	require.False(t, IsTimeout(err))
+}
+
+func TestWithRetry(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	notFound := &net.DNSError{Err: "no such host", Name: "api.3commas.invalid", IsNotFound: true}
+
+	cases := []struct {
+		name         string
+		maxRetries   int
+		failures     []error
+		wantAttempts int32
+		wantErr      error
+	}{
+		{
+			name:         "recovers from temporary errors",
+			maxRetries:   3,
+			failures:     []error{refused, refused},
+			wantAttempts: 3,
+		},
+		{
+			name:         "gives up after max retries",
+			maxRetries:   2,
+			failures:     []error{refused, refused, refused, refused},
+			wantAttempts: 3,
+			wantErr:      refused,
+		},
+		{
+			name:         "does not retry permanent errors",
+			maxRetries:   3,
+			failures:     []error{notFound},
+			wantAttempts: 1,
+			wantErr:      notFound,
+		},
+		{
+			name:         "disabled",
+			maxRetries:   0,
+			failures:     []error{refused},
+			wantAttempts: 1,
+			wantErr:      refused,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			doer := doerFunc(func(*http.Request) (*http.Response, error) {
+				n := int(attempts.Add(1))
+				if n <= len(tc.failures) {
+					return nil, tc.failures[n-1]
+				}
+				return okResponse(`{"id": 123}`), nil
+			})
+
+			client, err := New3CommasClient(append(defaultTestOptions(),
+				withHTTPClient(doer),
+				WithRetry(tc.maxRetries, time.Millisecond),
+			)...)
+			require.NoError(t, err)
+
+			deal, err := client.GetDealForID(context.Background(), 123)
+			require.Equal(t, tc.wantAttempts, attempts.Load())
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 123, deal.Id)
+		})
+	}
+}
+
+func TestWithRetrySkipsNonIdempotent(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	var attempts atomic.Int32
+	doer := &retryDoer{
+		base: doerFunc(func(*http.Request) (*http.Response, error) {
+			attempts.Add(1)
+			return nil, refused
+		}),
+		clock:      realClock{},
+		maxRetries: 3,
+		backoff:    time.Millisecond,
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.3commas.io/public/api/ver1/deals/1/cancel", nil)
+	require.NoError(t, err)
+	_, err = doer.Do(req)
+	require.ErrorIs(t, err, refused)
+	require.Equal(t, int32(1), attempts.Load())
+}
+
+func TestWithRetryContextCanceled(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	clock := newFakeClock(time.Now())
+	var attempts atomic.Int32
+	doer := &retryDoer{
+		base: doerFunc(func(*http.Request) (*http.Response, error) {
+			attempts.Add(1)
+			return nil, refused
+		}),
+		clock:      clock,
+		maxRetries: 3,
+		backoff:    time.Minute,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.3commas.io/public/api/ver1/deals", nil)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := doer.Do(req)
+		done <- err
+	}()
+
+	clock.WaitForTimers(t, 1)
+	cancel()
+
+	err = <-done
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, err, refused)
+	require.Equal(t, int32(1), attempts.Load())
+}
+
+func TestWithTimeoutPerAttempt(t *testing.T) {
+	var attempts atomic.Int32
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		if attempts.Add(1) == 1 {
+			// A slow attempt, held until its own deadline passes
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}
+		return okResponse(`{"id": 123}`), nil
+	})
+
+	client, err := New3CommasClient(append(defaultTestOptions(),
+		withHTTPClient(doer),
+		WithRetry(2, time.Millisecond),
+		WithTimeoutPerAttempt(20*time.Millisecond),
+	)...)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	deal, err := client.GetDealForID(ctx, 123)
+	require.NoError(t, err)
+	require.Equal(t, 123, deal.Id)
+	require.Equal(t, int32(2), attempts.Load())
+}
+
+func TestWithTimeoutPerAttemptContextCanceled(t *testing.T) {
+	var attempts atomic.Int32
+	started := make(chan struct{})
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		attempts.Add(1)
+		close(started)
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+
+	client, err := New3CommasClient(append(defaultTestOptions(),
+		withHTTPClient(doer),
+		WithRetry(3, time.Millisecond),
+		WithTimeoutPerAttempt(time.Minute),
+	)...)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err = client.GetDealForID(ctx, 123)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, int32(1), attempts.Load())
+}
+
+func TestWithRetryInvalid(t *testing.T) {
+	_, err := New3CommasClient(append(defaultTestOptions(), WithRetry(-1, time.Second))...)
+	require.Error(t, err)
+	_, err = New3CommasClient(append(defaultTestOptions(), WithRetry(1, -time.Second))...)
+	require.Error(t, err)
+	_, err = New3CommasClient(append(defaultTestOptions(), WithTimeoutPerAttempt(-time.Second))...)
+	require.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package threecommas
+
+import (
+	"time"
+
+	"github.com/recomma/3commas-sdk-go/threecommas/eventparser"
+)
+
+// DealSnapshot is the state of a deal at a point in time, reconstructed from
+// its bot events by Deal.StateAt.
+type DealSnapshot struct {
+	// At is the time the snapshot was taken at.
+	At time.Time
+	// Events is the number of events folded into the snapshot.
+	Events int
+	// ActiveSafetyOrders counts the safety orders placed and not yet
+	// executed or cancelled.
+	ActiveSafetyOrders int
+	// CompletedSafetyOrders counts the executed safety orders.
+	CompletedSafetyOrders int
+	// InvestedQuote is the quote volume of the executed base and safety
+	// orders, BoughtBase their size in the base currency.
+	InvestedQuote float64
+	BoughtBase    float64
+	// TakeProfitPrice is the price of the open take profit, 0 when there is
+	// none.
+	TakeProfitPrice float64
+	// Closed is set once the take profit or stop loss filled, or the trade
+	// completed.
+	Closed bool
+}
+
+// StateAt folds the Events created up to and including t into a
+// DealSnapshot, e.g. to step through a deal while debugging or to chart it.
+func (d *Deal) StateAt(t time.Time) DealSnapshot {
+	snapshot := DealSnapshot{At: t}
+	if d == nil {
+		return snapshot
+	}
+
+	// Open and cancelling safety orders by fingerprint, a "Cancelling" event
+	// may or may not be followed by a "cancelled" one
+	open := make(map[string]int)
+	cancelling := make(map[string]int)
+	for _, event := range d.Events() {
+		if event.CreatedAt.After(t) {
+			break
+		}
+		snapshot.Events++
+		filled := event.Action == BotEventActionExecute || event.Action == BotEventAction(eventparser.ActionFinished)
+
+		switch event.OrderType {
+		case MarketOrderDealOrderTypeBase:
+			if filled {
+				snapshot.InvestedQuote += event.QuoteVolume
+				snapshot.BoughtBase += event.Size
+			}
+		case MarketOrderDealOrderTypeSafety, MarketOrderDealOrderTypeManualSafety:
+			fp := event.Fingerprint()
+			switch {
+			case event.Action == BotEventActionPlace:
+				open[fp]++
+			case event.Action == BotEventActionCancel && open[fp] > 0:
+				open[fp]--
+				cancelling[fp]++
+			case event.Action == BotEventActionCancelled && cancelling[fp] > 0:
+				cancelling[fp]--
+			case event.Action == BotEventActionCancelled && open[fp] > 0:
+				open[fp]--
+			case filled:
+				if open[fp] > 0 {
+					open[fp]--
+				}
+				snapshot.InvestedQuote += event.QuoteVolume
+				snapshot.BoughtBase += event.Size
+				snapshot.CompletedSafetyOrders++
+			}
+		case MarketOrderDealOrderTypeTakeProfit:
+			switch {
+			case event.Action == BotEventActionPlace:
+				snapshot.TakeProfitPrice = event.Price
+			case event.Action == BotEventActionCancel, event.Action == BotEventActionCancelled:
+				if event.Price == snapshot.TakeProfitPrice {
+					snapshot.TakeProfitPrice = 0
+				}
+			case filled:
+				snapshot.TakeProfitPrice = 0
+				snapshot.Closed = true
+			}
+		case MarketOrderDealOrderTypeStopLoss:
+			if filled {
+				snapshot.Closed = true
+			}
+		}
+		if event.Action == BotEventAction(eventparser.ActionCompleted) {
+			snapshot.Closed = true
+		}
+	}
+
+	for _, n := range open {
+		snapshot.ActiveSafetyOrders += n
+	}
+	return snapshot
+}
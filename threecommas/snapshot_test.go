@@ -0,0 +1,89 @@
+package threecommas
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDealStateAt(t *testing.T) {
+	var deal Deal
+	require.NoError(t, json.Unmarshal([]byte(exampleDeal), &deal))
+
+	at := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339Nano, s)
+		require.NoError(t, err)
+		return ts
+	}
+
+	cases := []struct {
+		name string
+		at   time.Time
+		want DealSnapshot
+	}{
+		{
+			name: "before the deal",
+			at:   at("2025-09-25T18:00:00Z"),
+		},
+		{
+			name: "all safety orders placed",
+			at:   at("2025-09-25T18:53:54.215Z"),
+			want: DealSnapshot{
+				Events:             12,
+				ActiveSafetyOrders: 9,
+				InvestedQuote:      23.8966728,
+				BoughtBase:         105,
+				TakeProfitPrice:    0.23238,
+			},
+		},
+		{
+			name: "take profit being replaced",
+			at:   at("2025-09-25T19:50:39.952Z"),
+			want: DealSnapshot{
+				Events:                19,
+				ActiveSafetyOrders:    7,
+				CompletedSafetyOrders: 2,
+				InvestedQuote:         24.0966726 + 23.8966728 + 24.04680278,
+				BoughtBase:            317,
+			},
+		},
+		{
+			name: "take profit replaced",
+			at:   at("2025-09-25T19:50:40.022Z"),
+			want: DealSnapshot{
+				Events:                20,
+				ActiveSafetyOrders:    7,
+				CompletedSafetyOrders: 2,
+				InvestedQuote:         24.0966726 + 23.8966728 + 24.04680278,
+				BoughtBase:            317,
+				TakeProfitPrice:       0.23204,
+			},
+		},
+		{
+			name: "closed",
+			at:   at("2025-09-27T00:00:00Z"),
+			want: DealSnapshot{
+				Events:                len(deal.Events()),
+				CompletedSafetyOrders: 9,
+				InvestedQuote:         239.84177217,
+				BoughtBase:            1063,
+				Closed:                true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := deal.StateAt(tc.at)
+			require.InDelta(t, tc.want.InvestedQuote, got.InvestedQuote, 1e-8)
+			require.InDelta(t, tc.want.BoughtBase, got.BoughtBase, 1e-8)
+			tc.want.At = tc.at
+			tc.want.InvestedQuote, tc.want.BoughtBase = got.InvestedQuote, got.BoughtBase
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	require.Equal(t, DealSnapshot{At: at("2025-09-27T00:00:00Z")}, (*Deal)(nil).StateAt(at("2025-09-27T00:00:00Z")))
+}
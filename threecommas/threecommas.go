@@ -1,6 +1,7 @@
 package threecommas
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"crypto/rand"
@@ -8,11 +9,18 @@ import (
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"os"
 	"sort"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // ThreeCommasClientOption configures the 3commas client wrapper.
@@ -40,12 +48,24 @@ func WithThreeCommasBaseURL(url string) ThreeCommasClientOption {
 	}
 }
 
+// WithThreeCommasBaseURLFromEnv sets the base URL from the environment
+// variable envKey, e.g. to point tests at a mock server. It does nothing when
+// the variable is unset or empty, keeping the default or an earlier
+// WithThreeCommasBaseURL.
+func WithThreeCommasBaseURLFromEnv(envKey string) ThreeCommasClientOption {
+	return func(c *ThreeCommasClient) {
+		if baseURL := os.Getenv(envKey); baseURL != "" {
+			c.baseURL = baseURL
+		}
+	}
+}
+
 // WithPlanTier sets the subscription plan tier for rate limiting.
 // Defaults to PlanExpert.
 func WithPlanTier(tier PlanTier) ThreeCommasClientOption {
-	return func(c *ThreeCommasClient) {
+	return rateLimitOption(func(c *ThreeCommasClient) {
 		c.planTier = tier
-	}
+	})
 }
 
 // WithClientOption allows passing through oapi-codegen ClientOptions for middleware,
@@ -56,6 +76,89 @@ func WithClientOption(opt ClientOption) ThreeCommasClientOption {
 	}
 }
 
+// ResponseInterceptorFn is the function signature for inspecting or modifying
+// a response right after it was received, before the rate limiter observes it.
+type ResponseInterceptorFn func(resp *http.Response) error
+
+// WithResponseInterceptor installs a hook that is invoked for every response
+// before the rate limiter reacts to its status code. This can be used to
+// transform responses or to inject faults (e.g. a 429) in tests.
+// Multiple interceptors are invoked in the order they were added.
+func WithResponseInterceptor(fn ResponseInterceptorFn) ThreeCommasClientOption {
+	return func(c *ThreeCommasClient) {
+		c.responseInterceptors = append(c.responseInterceptors, fn)
+	}
+}
+
+// WithProxy routes all requests through the given HTTP(S) or SOCKS5 proxy,
+// e.g. "http://proxy.internal:3128". The URL is validated by New3CommasClient.
+// The proxied HTTP client replaces one set through WithClientOption.
+func WithProxy(proxyURL string) ThreeCommasClientOption {
+	return func(c *ThreeCommasClient) {
+		c.proxyURL = proxyURL
+	}
+}
+
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: unsupported scheme %q", raw, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", raw)
+	}
+	return u, nil
+}
+
+// WithHeader adds a header to every request, e.g. a token required by a
+// gateway. It can be repeated, also for the same key to send multiple values.
+// The headers are set after signing and don't affect the signature; the
+// Apikey and Signature headers (see WithSignatureHeaderNames) are reserved and
+// rejected by New3CommasClient.
+func WithHeader(key, value string) ThreeCommasClientOption {
+	return func(c *ThreeCommasClient) {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		c.headers.Add(key, value)
+	}
+}
+
+func headerEditor(headers http.Header) RequestEditorFn {
+	return func(_ context.Context, req *http.Request) error {
+		for key, values := range headers {
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+		return nil
+	}
+}
+
+// WithSignatureHeaderNames sets the names of the headers carrying the API key
+// and the request signature, for proxies that rename them. The defaults are
+// Apikey and Signature. The signed payload is not affected.
+func WithSignatureHeaderNames(apiKeyHeader, signatureHeader string) ThreeCommasClientOption {
+	return func(c *ThreeCommasClient) {
+		c.apiKeyHeader = apiKeyHeader
+		c.sigHeader = signatureHeader
+	}
+}
+
+// WithStrictDecoding makes the wrapper methods (GetListOfDeals, ListBots,
+// GetDealForID, ...) fail when a response has fields unknown to the generated
+// models, which helps to catch API changes in tests. The default is lenient.
+func WithStrictDecoding() ThreeCommasClientOption {
+	return func(c *ThreeCommasClient) {
+		c.strictDecoding = true
+	}
+}
+
 // withHTTPClient is an internal option for testing
 func withHTTPClient(client HttpRequestDoer) ThreeCommasClientOption {
 	return func(c *ThreeCommasClient) {
@@ -66,8 +169,12 @@ func withHTTPClient(client HttpRequestDoer) ThreeCommasClientOption {
 // New3CommasClient creates a fully-wired client with RSA signing and rate limiting.
 func New3CommasClient(opts ...ThreeCommasClientOption) (*ThreeCommasClient, error) {
 	tc := &ThreeCommasClient{
-		baseURL:  "https://api.3commas.io/public/api",
-		planTier: PlanExpert,
+		baseURL:      "https://api.3commas.io/public/api",
+		planTier:     PlanExpert,
+		clock:        realClock{},
+		apiKeyHeader: "Apikey",
+		sigHeader:    "Signature",
+		maxBodyBytes: defaultMaxResponseBytes,
 	}
 
 	// Apply wrapper configuration
@@ -75,6 +182,18 @@ func New3CommasClient(opts ...ThreeCommasClientOption) (*ThreeCommasClient, erro
 		opt(tc)
 	}
 
+	if tc.clock == nil {
+		tc.clock = realClock{}
+	}
+
+	if tc.credsFile != "" {
+		apiKey, privatePEM, err := readCredentialsFile(tc.credsFile)
+		if err != nil {
+			return nil, err
+		}
+		tc.apiKey, tc.privatePEM = apiKey, privatePEM
+	}
+
 	// Validate required fields
 	if tc.apiKey == "" {
 		return nil, fmt.Errorf("API key is required")
@@ -83,23 +202,77 @@ func New3CommasClient(opts ...ThreeCommasClientOption) (*ThreeCommasClient, erro
 		return nil, fmt.Errorf("private key PEM is required")
 	}
 
+	if tc.maxRetries < 0 || tc.retryBackoff < 0 {
+		return nil, fmt.Errorf("retry: max retries and backoff must not be negative")
+	}
+	if tc.attemptTimeout < 0 {
+		return nil, fmt.Errorf("retry: timeout per attempt must not be negative")
+	}
+	if tc.maxBodyBytes <= 0 {
+		return nil, fmt.Errorf("max response bytes must be positive")
+	}
+
+	if tc.apiKeyHeader == "" || tc.sigHeader == "" {
+		return nil, fmt.Errorf("signature header names must not be empty")
+	}
+	apiKeyHeader := http.CanonicalHeaderKey(tc.apiKeyHeader)
+	sigHeader := http.CanonicalHeaderKey(tc.sigHeader)
+	if apiKeyHeader == sigHeader {
+		return nil, fmt.Errorf("signature header names must differ")
+	}
+
+	for key := range tc.headers {
+		if key == apiKeyHeader || key == sigHeader {
+			return nil, fmt.Errorf("header %s is set by the client and cannot be overridden", key)
+		}
+	}
+
 	// Build RSA signer
 	priv, err := parseRSAPrivate(tc.privatePEM)
 	if err != nil {
 		return nil, err
 	}
-	signer := newRSASigner(tc.apiKey, priv)
+	if err := checkSigningKey(priv); err != nil {
+		return nil, err
+	}
+	signer := newRSASigner(tc.apiKey, priv, apiKeyHeader, sigHeader)
+
+	var proxy *url.URL
+	if tc.proxyURL != "" {
+		if proxy, err = parseProxyURL(tc.proxyURL); err != nil {
+			return nil, err
+		}
+	}
 
 	// Build ClientOptions: user options first, then auth, then rate limit
 	clientOpts := append([]ClientOption{}, tc.clientOptions...)
-	clientOpts = append(clientOpts,
-		WithRequestEditorFn(signer),
-		WithThreeCommasRateLimits(tc.planTier),
-	)
 
-	// If a custom HTTP client was provided (for testing), use it
+	// If a custom HTTP client was provided (for testing), use it. This must
+	// come before the rate limiter so it gets wrapped instead of replacing it.
 	if tc.httpClient != nil {
 		clientOpts = append(clientOpts, WithHTTPClient(tc.httpClient))
+	} else if proxy != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxy)
+		clientOpts = append(clientOpts, WithHTTPClient(&http.Client{Transport: transport}))
+	}
+	clientOpts = append(clientOpts, withErrorBodyDoer(), withLimitBodyDoer(tc.maxBodyBytes))
+
+	if tc.sharedLimiter != nil {
+		tc.rateLimits = tc.sharedLimiter.eng
+	} else if tc.rateLimits, err = newConfiguredRLEngine(tc); err != nil {
+		return nil, err
+	}
+	clientOpts = append(clientOpts, WithRequestEditorFn(signer))
+	if len(tc.headers) > 0 {
+		clientOpts = append(clientOpts, WithRequestEditorFn(headerEditor(tc.headers)))
+	}
+	if tc.traceWriter != nil {
+		clientOpts = append(clientOpts, withTraceDoer(tc.traceWriter, apiKeyHeader, sigHeader))
+	}
+	clientOpts = append(clientOpts, withRateLimitDoer(tc.rateLimits, tc.responseInterceptors))
+	if tc.maxRetries > 0 || tc.attemptTimeout > 0 {
+		clientOpts = append(clientOpts, withRetryDoer(tc.clock, tc.maxRetries, tc.retryBackoff, tc.attemptTimeout))
 	}
 
 	// Build underlying client
@@ -133,7 +306,26 @@ func parseRSAPrivate(pemBytes []byte) (*rsa.PrivateKey, error) {
 	return rsaKey, nil
 }
 
-func newRSASigner(apiKey string, priv *rsa.PrivateKey) RequestEditorFn {
+// ErrSigning is matched (via errors.Is) by errors caused by signing requests.
+var ErrSigning = errors.New("signing failed")
+
+// minRSAKeyBits is the smallest RSA key New3CommasClient accepts.
+const minRSAKeyBits = 2048
+
+// checkSigningKey validates the key so unusable keys are reported by
+// New3CommasClient instead of on the first request. Keys below minRSAKeyBits
+// are rejected, independent of the minimum crypto/rsa enforces itself.
+func checkSigningKey(priv *rsa.PrivateKey) error {
+	if err := priv.Validate(); err != nil {
+		return fmt.Errorf("%w: unusable RSA key: %w", ErrSigning, err)
+	}
+	if bits := priv.N.BitLen(); bits < minRSAKeyBits {
+		return fmt.Errorf("%w: RSA key of %d bits is below the minimum of %d bits", ErrSigning, bits, minRSAKeyBits)
+	}
+	return nil
+}
+
+func newRSASigner(apiKey string, priv *rsa.PrivateKey, apiKeyHeader, sigHeader string) RequestEditorFn {
 	return func(_ context.Context, req *http.Request) error {
 		payload := req.URL.EscapedPath()
 		if qs := sortedQuery(req); qs != "" {
@@ -143,11 +335,11 @@ func newRSASigner(apiKey string, priv *rsa.PrivateKey) RequestEditorFn {
 		digest := sha256.Sum256([]byte(payload))
 		rawSig, err := rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest[:])
 		if err != nil {
-			return fmt.Errorf("rsa sign: %w", err)
+			return fmt.Errorf("%w: rsa sign: %w", ErrSigning, err)
 		}
 
-		req.Header.Set("Apikey", apiKey)
-		req.Header.Set("Signature", base64.StdEncoding.EncodeToString(rawSig))
+		req.Header.Set(apiKeyHeader, apiKey)
+		req.Header.Set(sigHeader, base64.StdEncoding.EncodeToString(rawSig))
 		return nil
 	}
 }
@@ -157,19 +349,85 @@ func sortedQuery(r *http.Request) string {
 		return ""
 	}
 	parts := strings.Split(r.URL.RawQuery, "&")
-	sort.Strings(parts) // 3Commas examples sort lexicographically
+	// 3Commas examples sort lexicographically. Sort on the key only, so the
+	// values of a repeated (array) param like bot_ids[] keep their order.
+	sort.SliceStable(parts, func(i, j int) bool {
+		return queryKey(parts[i]) < queryKey(parts[j])
+	})
 	return strings.Join(parts, "&")
 }
 
+func queryKey(part string) string {
+	key, _, _ := strings.Cut(part, "=")
+	return key
+}
+
 type ThreeCommasClient struct {
 	*ClientWithResponses
 
-	baseURL       string
-	apiKey        string
-	privatePEM    []byte
-	planTier      PlanTier
-	httpClient    HttpRequestDoer
-	clientOptions []ClientOption
+	baseURL        string
+	proxyURL       string
+	apiKey         string
+	apiKeyHeader   string
+	sigHeader      string
+	privatePEM     []byte
+	credsFile      string
+	planTier       PlanTier
+	tierWindow     time.Duration
+	blockBehavior  BlockBehavior
+	maxLimitWait   time.Duration
+	limitBackoff   time.Duration
+	sharedLimiter  *RateLimiter
+	rateLimitOpt   bool // set by the last applied option if it is a rate limit option
+	strictDecoding bool
+	maxRetries     int
+	retryBackoff   time.Duration
+	attemptTimeout time.Duration
+	maxBodyBytes   int64
+	clock          Clock
+	httpClient     HttpRequestDoer
+	clientOptions  []ClientOption
+	headers        http.Header
+	traceWriter    io.Writer
+
+	responseInterceptors []ResponseInterceptorFn
+	rateLimits           *rlEngine
+	closed               atomic.Bool
+}
+
+// Close releases the resources held by the client. It currently closes the
+// idle connections of the underlying HTTP client. Close is idempotent; calls
+// after the first do nothing and return nil.
+func (c *ThreeCommasClient) Close() error {
+	if !c.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	if c.ClientWithResponses == nil {
+		return nil
+	}
+	if raw, ok := c.ClientInterface.(*Client); ok {
+		closeIdleConnections(raw.Client)
+	}
+	return nil
+}
+
+// closeIdleConnections unwraps the doers installed by New3CommasClient and
+// closes the idle connections of the HTTP client underneath.
+func closeIdleConnections(doer HttpRequestDoer) {
+	switch d := doer.(type) {
+	case *retryDoer:
+		closeIdleConnections(d.base)
+	case *rateLimitDoer:
+		closeIdleConnections(d.base)
+	case *traceDoer:
+		closeIdleConnections(d.base)
+	case *limitBodyDoer:
+		closeIdleConnections(d.base)
+	case *errorBodyDoer:
+		closeIdleConnections(d.base)
+	case interface{ CloseIdleConnections() }:
+		d.CloseIdleConnections()
+	}
 }
 
 func (c *ThreeCommasClient) GetMarketOrdersForDeal(ctx context.Context, dealId DealPathId) ([]MarketOrder, error) {
@@ -188,13 +446,21 @@ func (c *ThreeCommasClient) GetTradesForDeal(ctx context.Context, dealId DealPat
 		return nil, err
 	}
 
-	return *resp.JSON200, nil
+	result, err := decoded(c, resp.JSON200, resp.HTTPResponse, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return *result, nil
 }
 
 // GetListOfDeals is a thin wrapper around ListDealsWithResponse that
 // returns the slice of Deal on 200 OK, or an error otherwise.
 func (c *ThreeCommasClient) GetListOfDeals(ctx context.Context, opts ...ListDealsParamsOption) ([]Deal, error) {
 	p := ListDealsParamsFromOptions(opts...)
+	if err := validateListDealsParams(p); err != nil {
+		return nil, err
+	}
 	resp, err := c.ListDealsWithResponse(ctx, p)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w, params: %v", err, p)
@@ -204,7 +470,73 @@ func (c *ThreeCommasClient) GetListOfDeals(ctx context.Context, opts ...ListDeal
 		return nil, err
 	}
 
-	return *resp.JSON200, nil
+	result, err := decoded(c, resp.JSON200, resp.HTTPResponse, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return *result, nil
+}
+
+// defaultDealsPageSize is the page size used when paginating deals without
+// an explicit limit.
+const defaultDealsPageSize = 100
+
+// GetDealsUpdatedSince returns the deals updated after since, most recently
+// updated first. It orders the list by updated_at descending (overriding any
+// order given in opts) and pages through it, stopping at the first deal that
+// was not updated after since instead of walking the whole history.
+func (c *ThreeCommasClient) GetDealsUpdatedSince(ctx context.Context, since time.Time, opts ...ListDealsParamsOption) ([]Deal, error) {
+	p := ListDealsParamsFromOptions(opts...)
+
+	order := ListDealsParamsOrderUpdatedAt
+	direction := ListDealsParamsOrderDirectionDESC
+	p.Order = &order
+	p.OrderDirection = &direction
+
+	limit := defaultDealsPageSize
+	if p.Limit != nil && *p.Limit > 0 {
+		limit = *p.Limit
+	}
+	p.Limit = &limit
+	if err := validateListDealsParams(p); err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	if p.Offset != nil {
+		offset = *p.Offset
+	}
+
+	var deals []Deal
+	for {
+		p.Offset = &offset
+		resp, err := c.ListDealsWithResponse(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w, params: %v", err, p)
+		}
+
+		if err := GetErrorFromResponse(resp); err != nil {
+			return nil, err
+		}
+
+		page, err := decoded(c, resp.JSON200, resp.HTTPResponse, resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, deal := range *page {
+			if !deal.UpdatedAt.After(since) {
+				return deals, nil
+			}
+			deals = append(deals, deal)
+		}
+
+		if len(*page) < limit {
+			return deals, nil
+		}
+		offset += len(*page)
+	}
 }
 
 // ListBots is a thin wrapper around ListBotsWithResponse that
@@ -220,7 +552,12 @@ func (c *ThreeCommasClient) ListBots(ctx context.Context, opts ...ListBotsParams
 		return nil, err
 	}
 
-	return *resp.JSON200, nil
+	result, err := decoded(c, resp.JSON200, resp.HTTPResponse, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return *result, nil
 }
 
 func (c *ThreeCommasClient) GetDealForID(ctx context.Context, dealId DealPathId) (*Deal, error) {
@@ -233,23 +570,133 @@ func (c *ThreeCommasClient) GetDealForID(ctx context.Context, dealId DealPathId)
 		return nil, err
 	}
 
-	deal := Deal(*resp.JSON200)
+	result, err := decoded(c, resp.JSON200, resp.HTTPResponse, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	deal := Deal(*result)
 
 	return &deal, nil
 }
 
+// Ping validates the credentials against the API, e.g. to fail fast at
+// startup. Invalid or expired credentials yield an error matching
+// ErrUnauthorized.
+func (c *ThreeCommasClient) Ping(ctx context.Context) error {
+	resp, err := c.ValidateAuthenticationWithResponse(ctx)
+	if err != nil {
+		return fmt.Errorf("ping: request failed: %w", err)
+	}
+
+	if err := GetErrorFromResponse(resp); err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+
+	result, err := decoded(c, resp.JSON200, resp.HTTPResponse, resp.Body)
+	if err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	if !result.Valid {
+		return fmt.Errorf("ping: credentials not valid: %w", ErrUnauthorized)
+	}
+	return nil
+}
+
+// GetDealWithOrders fetches the deal and its market orders in one go.
+// The orders are only requested once the deal was fetched successfully.
+func (c *ThreeCommasClient) GetDealWithOrders(ctx context.Context, dealId DealPathId) (*Deal, []MarketOrder, error) {
+	deal, err := c.GetDealForID(ctx, dealId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orders, err := c.GetMarketOrdersForDeal(ctx, dealId)
+	if err != nil {
+		return deal, nil, err
+	}
+
+	return deal, orders, nil
+}
+
+// ErrUnexpectedResponse is returned when a successful response carries a body
+// that could not be decoded, e.g. an HTML page served by a proxy.
+var ErrUnexpectedResponse = errors.New("unexpected response")
+
+func unexpectedResponse(resp *http.Response) error {
+	return fmt.Errorf("%w: status %d with content type %q",
+		ErrUnexpectedResponse, resp.StatusCode, resp.Header.Get("Content-Type"))
+}
+
+// decoded returns the decoded 200 body v, or an error when it is missing or,
+// with WithStrictDecoding, when body has fields unknown to the model.
+func decoded[T any](c *ThreeCommasClient, v *T, resp *http.Response, body []byte) (*T, error) {
+	if v == nil {
+		return nil, unexpectedResponse(resp)
+	}
+	if c.strictDecoding {
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(new(T)); err != nil {
+			return nil, fmt.Errorf("strict decoding: %w", err)
+		}
+	}
+	return v, nil
+}
+
+func parseErrorResponse(body []byte) *ErrorResponse {
+	var payload ErrorResponse
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil
+	}
+	return &payload
+}
+
+// ErrUnauthorized is matched (via errors.Is) by errors for requests rejected
+// because of invalid or expired credentials, including an *APIError with
+// status 401.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// maxRawBodySize bounds how much of the response body is kept on an APIError.
+const maxRawBodySize = 64 << 10
+
 // APIError wraps the raw ErrorResponse plus the HTTP status code.
 type APIError struct {
-	StatusCode   int
+	StatusCode int
+	// ErrorPayload is nil when the body is not an ErrorResponse, e.g. an HTML
+	// error page or truncated JSON.
 	ErrorPayload *ErrorResponse
+	// RawBody holds the unparsed response body (truncated to 64KiB), useful
+	// when the body does not match the ErrorResponse schema.
+	RawBody []byte
 }
 
 // Error implements the error interface.
 func (e *APIError) Error() string {
-	if e.ErrorPayload.ErrorDescription != nil {
+	if e == nil {
+		return "API error"
+	}
+	switch {
+	case e.ErrorPayload != nil && e.ErrorPayload.ErrorDescription != nil:
 		return fmt.Sprintf("API error %d: %s", e.StatusCode, *e.ErrorPayload.ErrorDescription)
+	case e.ErrorPayload != nil && e.ErrorPayload.Error != "":
+		return fmt.Sprintf("API error %d: %s", e.StatusCode, e.ErrorPayload.Error)
 	}
-	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.ErrorPayload.Error)
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is makes errors.Is(err, ErrUnauthorized) hold for 401 responses.
+func (e *APIError) Is(target error) bool {
+	return target == ErrUnauthorized && e != nil && e.StatusCode == http.StatusUnauthorized
+}
+
+// ValidationErrors returns the field errors of a 422 response keyed by field
+// name, or nil when the response has none.
+func (e *APIError) ValidationErrors() map[string][]string {
+	if e == nil || e.ErrorPayload == nil || e.ErrorPayload.ErrorAttributes == nil {
+		return nil
+	}
+	return *e.ErrorPayload.ErrorAttributes
 }
 
 func (e *ErrorResponse) String() string {
@@ -277,6 +724,9 @@ func (e *ErrorResponse) String() string {
 	return s.String()
 }
 
+// GetErrorFromResponse returns an *APIError for responses outside the 2xx
+// range, nil otherwise. The error carries the status code and the raw body
+// even when the body is not an ErrorResponse.
 func GetErrorFromResponse(v APIErrorResponses) error {
 	// Treat anything in the 200–299 range as OK:
 	if 200 <= v.StatusCode() && v.StatusCode() <= 299 {
@@ -301,13 +751,23 @@ func GetErrorFromResponse(v APIErrorResponses) error {
 	case 504:
 		payload = v.GetJSON504()
 	default:
-		// we don’t have an ErrorResponse type for this code
-		return nil
+		// Not described by the spec, decode the body if it is an ErrorResponse
+		payload = parseErrorResponse(v.GetBody())
 	}
 
-	if payload == nil {
-		// status code but no JSON body → treat as non-API error
-		return nil
+	// payload is nil for bodies that are not an ErrorResponse, e.g. an HTML
+	// error page, RawBody keeps them
+	return &APIError{
+		StatusCode:   v.StatusCode(),
+		ErrorPayload: payload,
+		RawBody:      truncateBody(v.GetBody(), maxRawBodySize),
+	}
+}
+
+// truncateBody returns a copy of body holding at most limit bytes.
+func truncateBody(body []byte, limit int) []byte {
+	if len(body) > limit {
+		body = body[:limit]
 	}
-	return &APIError{v.StatusCode(), payload}
+	return append([]byte(nil), body...)
 }
@@ -0,0 +1,225 @@
+// Package threecommastest provides a mock 3commas API server for testing code
+// that uses the threecommas client, without real credentials or recorded
+// cassettes.
+package threecommastest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/recomma/3commas-sdk-go/threecommas"
+)
+
+// basePath is the path prefix of the 3commas API, stripped from the request
+// paths the Server matches on.
+const basePath = "/public/api"
+
+// Request is a request received by the Server.
+type Request struct {
+	Method string
+	// Path is relative to the API base, e.g. "/ver1/deals/123/show".
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+type response struct {
+	status int
+	body   []byte
+}
+
+// Server is a mock of the 3commas API. Responses are enqueued per method and
+// path and served once each, in order. Requests without the Apikey and
+// Signature headers get a 401, requests without an enqueued response a 404.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[string][]response
+	requests  []Request
+}
+
+// NewServer starts a Server. Callers should Close it when done.
+func NewServer() *Server {
+	s := &Server{responses: make(map[string][]response)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// BaseURL is the URL to pass to threecommas.WithThreeCommasBaseURL.
+func (s *Server) BaseURL() string {
+	return s.URL + basePath
+}
+
+// ClientOptions returns the options to create a client for the Server with a
+// throwaway API key and private key.
+func (s *Server) ClientOptions() []threecommas.ThreeCommasClientOption {
+	return []threecommas.ThreeCommasClientOption{
+		threecommas.WithThreeCommasBaseURL(s.BaseURL()),
+		threecommas.WithAPIKey("threecommastest"),
+		threecommas.WithPrivatePEM(testKey()),
+	}
+}
+
+// Enqueue adds a response for the method and path, e.g. "/ver1/deals". The
+// body is sent as is when it is a []byte or string, else it is encoded as
+// JSON.
+func (s *Server) Enqueue(method, path string, status int, body any) *Server {
+	var data []byte
+	switch b := body.(type) {
+	case []byte:
+		data = b
+	case string:
+		data = []byte(b)
+	default:
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			panic(fmt.Sprintf("threecommastest: encode body for %s %s: %v", method, path, err))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := routeKey(method, path)
+	s.responses[key] = append(s.responses[key], response{status: status, body: data})
+	return s
+}
+
+// EnqueueDeal adds a response with the deal for GET /ver1/deals/{id}/show.
+func (s *Server) EnqueueDeal(deal threecommas.Deal) *Server {
+	return s.Enqueue(http.MethodGet, fmt.Sprintf("/ver1/deals/%d/show", deal.Id), http.StatusOK, deal)
+}
+
+// EnqueueDeals adds a response with the deals for GET /ver1/deals.
+func (s *Server) EnqueueDeals(deals []threecommas.Deal) *Server {
+	return s.Enqueue(http.MethodGet, "/ver1/deals", http.StatusOK, nonNil(deals))
+}
+
+// EnqueueBots adds a response with the bots for GET /ver1/bots.
+func (s *Server) EnqueueBots(bots []threecommas.Bot) *Server {
+	return s.Enqueue(http.MethodGet, "/ver1/bots", http.StatusOK, nonNil(bots))
+}
+
+// EnqueueError adds an error response in the format of the 3commas API.
+func (s *Server) EnqueueError(method, path string, status int, code, description string) *Server {
+	return s.Enqueue(method, path, status, threecommas.ErrorResponse{
+		Error:            code,
+		ErrorDescription: &description,
+	})
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// AssertRequested fails t unless a request for the method and path was
+// received.
+func (s *Server) AssertRequested(t testing.TB, method, path string) {
+	t.Helper()
+	for _, req := range s.Requests() {
+		if req.Method == method && req.Path == path {
+			return
+		}
+	}
+	t.Errorf("threecommastest: no request for %s %s", method, path)
+}
+
+// AssertAllServed fails t for every enqueued response that was not served.
+func (s *Server) AssertAllServed(t testing.TB) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, pending := range s.responses {
+		if len(pending) > 0 {
+			t.Errorf("threecommastest: %d response(s) for %s not served", len(pending), key)
+		}
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	path := strings.TrimPrefix(r.URL.Path, basePath)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	var resp response
+	if r.Header.Get("Apikey") == "" || r.Header.Get("Signature") == "" {
+		resp = errorResponse(http.StatusUnauthorized, "api_key_invalid_or_expired", "Unauthorized. Missing Apikey or Signature header.")
+	} else {
+		resp = s.next(routeKey(r.Method, path))
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
+
+// next pops the next response for key, or returns a 404 response when none is
+// enqueued. Must be called with s.mu held.
+func (s *Server) next(key string) response {
+	pending := s.responses[key]
+	if len(pending) == 0 {
+		return errorResponse(http.StatusNotFound, "not_found", "threecommastest: no response enqueued for "+key)
+	}
+	s.responses[key] = pending[1:]
+	return pending[0]
+}
+
+func errorResponse(status int, code, description string) response {
+	body, _ := json.Marshal(threecommas.ErrorResponse{Error: code, ErrorDescription: &description})
+	return response{status: status, body: body}
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// nonNil makes a nil slice encode as [] like the API does.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+var (
+	keyOnce sync.Once
+	keyPEM  []byte
+)
+
+// testKey returns a private key PEM generated once per process.
+func testKey() []byte {
+	keyOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(fmt.Sprintf("threecommastest: generate key: %v", err))
+		}
+		var buf bytes.Buffer
+		pem.Encode(&buf, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		keyPEM = buf.Bytes()
+	})
+	return keyPEM
+}
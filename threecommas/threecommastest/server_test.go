@@ -0,0 +1,119 @@
+package threecommastest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/recomma/3commas-sdk-go/threecommas"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, srv *Server) *threecommas.ThreeCommasClient {
+	t.Helper()
+	client, err := threecommas.New3CommasClient(srv.ClientOptions()...)
+	require.NoError(t, err)
+	return client
+}
+
+func TestServerDealsAndBots(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.EnqueueDeal(threecommas.Deal{Id: 123, BotId: 7, Pair: "USDT_DOGE", Status: threecommas.DealStatusBought}).
+		EnqueueDeals([]threecommas.Deal{{Id: 1}, {Id: 2}}).
+		EnqueueBots(nil)
+
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	deal, err := client.GetDealForID(ctx, 123)
+	require.NoError(t, err)
+	require.Equal(t, 7, deal.BotId)
+	require.Equal(t, "USDT_DOGE", deal.Pair)
+
+	deals, err := client.GetListOfDeals(ctx, threecommas.WithBotIdForListDeals(7))
+	require.NoError(t, err)
+	require.Len(t, deals, 2)
+
+	bots, err := client.ListBots(ctx)
+	require.NoError(t, err)
+	require.Empty(t, bots)
+
+	srv.AssertRequested(t, http.MethodGet, "/ver1/deals/123/show")
+	srv.AssertRequested(t, http.MethodGet, "/ver1/bots")
+	srv.AssertAllServed(t)
+
+	requests := srv.Requests()
+	require.Len(t, requests, 3)
+	require.Equal(t, "/ver1/deals", requests[1].Path)
+	require.Equal(t, "7", requests[1].Query.Get("bot_id"))
+	require.Equal(t, "threecommastest", requests[1].Header.Get("Apikey"))
+	require.NotEmpty(t, requests[1].Header.Get("Signature"))
+}
+
+func TestServerServesResponsesOnce(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.EnqueueDeal(threecommas.Deal{Id: 1, Status: threecommas.DealStatusBought}).
+		EnqueueDeal(threecommas.Deal{Id: 1, Status: threecommas.DealStatusCompleted})
+
+	client := newClient(t, srv)
+	for _, want := range []threecommas.DealStatus{threecommas.DealStatusBought, threecommas.DealStatusCompleted} {
+		deal, err := client.GetDealForID(context.Background(), 1)
+		require.NoError(t, err)
+		require.Equal(t, want, deal.Status)
+	}
+
+	// Nothing left to serve
+	_, err := client.GetDealForID(context.Background(), 1)
+	var apiErr *threecommas.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	require.ErrorContains(t, err, "no response enqueued for GET /ver1/deals/1/show")
+}
+
+func TestServerErrors(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.EnqueueError(http.MethodGet, "/ver1/bots", http.StatusUnprocessableEntity, "record_invalid", "Invalid parameters")
+	_, err := newClient(t, srv).ListBots(context.Background())
+	require.EqualError(t, err, "API error 422: Invalid parameters")
+
+	// Unsigned requests are rejected without consuming a response
+	srv.EnqueueBots(nil)
+	resp, err := http.Get(srv.BaseURL() + "/ver1/bots")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	_, err = newClient(t, srv).ListBots(context.Background())
+	require.NoError(t, err)
+}
+
+func TestServerAssertions(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.EnqueueDeals(nil)
+
+	rec := &recordingTB{TB: t}
+	srv.AssertRequested(rec, http.MethodGet, "/ver1/deals")
+	srv.AssertAllServed(rec)
+	require.Len(t, rec.errors, 2)
+	require.Contains(t, rec.errors[0], "no request for GET /ver1/deals")
+	require.Contains(t, rec.errors[1], "1 response(s) for GET /ver1/deals not served")
+}
+
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
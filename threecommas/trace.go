@@ -0,0 +1,77 @@
+package threecommas
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+// redactedHeaders are replaced in the dumps written by WithHTTPTrace, next to
+// the headers set with WithSignatureHeaderNames.
+var redactedHeaders = []string{"Apikey", "Signature", "Authorization"}
+
+// WithHTTPTrace writes a dump of every request and response to w, e.g. a log
+// file, to debug against the live API. The Apikey, Signature and Authorization
+// headers, or their names set with WithSignatureHeaderNames, are redacted.
+// Each attempt that reaches the network is written, requests held back by the
+// rate limiter are not.
+func WithHTTPTrace(w io.Writer) ThreeCommasClientOption {
+	return func(c *ThreeCommasClient) {
+		c.traceWriter = w
+	}
+}
+
+type traceDoer struct {
+	base   HttpRequestDoer
+	mu     sync.Mutex
+	w      io.Writer
+	redact []string
+}
+
+func (d *traceDoer) Do(req *http.Request) (*http.Response, error) {
+	// Dump a redacted clone, the clone's body is restored after dumping
+	dump := req.Clone(req.Context())
+	for _, key := range append(redactedHeaders, d.redact...) {
+		if dump.Header.Get(key) != "" {
+			dump.Header.Set(key, "REDACTED")
+		}
+	}
+	reqDump, err := httputil.DumpRequestOut(dump, true)
+	if err != nil {
+		return nil, fmt.Errorf("http trace: %w", err)
+	}
+	req.Body = dump.Body
+
+	resp, err := d.base.Do(req)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fmt.Fprintf(d.w, "%s\n", reqDump)
+	if err != nil {
+		fmt.Fprintf(d.w, "error: %v\n\n", err)
+		return resp, err
+	}
+	respDump, dumpErr := httputil.DumpResponse(resp, true)
+	if dumpErr != nil {
+		fmt.Fprintf(d.w, "error dumping response: %v\n\n", dumpErr)
+		return resp, nil
+	}
+	fmt.Fprintf(d.w, "%s\n\n", respDump)
+	return resp, nil
+}
+
+// withTraceDoer wraps the current Doer of the client with a traceDoer. It is
+// installed before the rate limiter so only requests sent are traced. The
+// redact headers are redacted next to the redactedHeaders.
+func withTraceDoer(w io.Writer, redact ...string) ClientOption {
+	return func(c *Client) error {
+		base := c.Client
+		if base == nil {
+			base = &http.Client{}
+		}
+		c.Client = &traceDoer{base: base, w: w, redact: redact}
+		return nil
+	}
+}
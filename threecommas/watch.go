@@ -0,0 +1,52 @@
+package threecommas
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WatchDeal polls the deal every interval and calls onNew with the events
+// that were not seen in the previous poll (see DiffEvents). The first poll
+// reports all events of the deal. Polls go through the rate limiter like any
+// other request.
+//
+// WatchDeal blocks until the deal reaches a terminal status (returning nil,
+// after reporting its final events), the context is cancelled (returning the
+// context's error) or a poll fails (returning that error).
+func (c *ThreeCommasClient) WatchDeal(ctx context.Context, dealId DealPathId, interval time.Duration, onNew func(newEvents []BotEvent)) error {
+	if interval <= 0 {
+		return fmt.Errorf("watch deal %d: interval must be positive", dealId)
+	}
+	return watchDeal(ctx, c.clock, interval, func(ctx context.Context) (*Deal, error) {
+		return c.GetDealForID(ctx, dealId)
+	}, onNew)
+}
+
+func watchDeal(ctx context.Context, clock Clock, interval time.Duration, poll func(context.Context) (*Deal, error), onNew func([]BotEvent)) error {
+	var prev []BotEvent
+	for {
+		deal, err := poll(ctx)
+		if err != nil {
+			return err
+		}
+
+		events := deal.Events()
+		if added := DiffEvents(prev, events); len(added) > 0 {
+			onNew(added)
+		}
+		prev = events
+
+		if deal.Status.IsTerminal() {
+			return nil
+		}
+
+		timer := clock.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
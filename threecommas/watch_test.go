@@ -0,0 +1,104 @@
+package threecommas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchDeal(t *testing.T) {
+	var full Deal
+	require.NoError(t, json.Unmarshal([]byte(exampleDeal), &full))
+
+	snapshot := func(n int, status DealStatus) *Deal {
+		deal := full
+		deal.BotEvents = full.BotEvents[:n]
+		deal.Status = status
+		return &deal
+	}
+
+	t.Run("reports new events until terminal", func(t *testing.T) {
+		snapshots := []*Deal{
+			snapshot(10, DealStatusBought),
+			snapshot(10, DealStatusBought),
+			snapshot(30, DealStatusBought),
+			snapshot(len(full.BotEvents), DealStatusCompleted),
+		}
+		polls := 0
+		poll := func(context.Context) (*Deal, error) {
+			deal := snapshots[polls]
+			polls++
+			return deal, nil
+		}
+
+		var calls [][]BotEvent
+		clock := newFakeClock(time.Now())
+		done := make(chan error, 1)
+		go func() {
+			done <- watchDeal(context.Background(), clock, time.Minute, poll, func(events []BotEvent) {
+				calls = append(calls, events)
+			})
+		}()
+
+		for range len(snapshots) - 1 {
+			clock.WaitForTimers(t, 1)
+			clock.Advance(time.Minute)
+		}
+		require.NoError(t, <-done)
+		require.Equal(t, len(snapshots), polls)
+
+		// The unchanged second poll doesn't invoke the callback
+		require.Len(t, calls, 3)
+		require.Len(t, calls[0], 10)
+		require.Len(t, calls[1], 20)
+
+		// The snapshots take raw events out of time order, so the
+		// SequenceIndex of earlier reports differs from the final one
+		withoutIndex := func(events []BotEvent) []BotEvent {
+			for i := range events {
+				events[i].SequenceIndex = 0
+			}
+			return events
+		}
+		var seen []BotEvent
+		for _, events := range calls {
+			seen = append(seen, events...)
+		}
+		require.ElementsMatch(t, withoutIndex(full.Events()), withoutIndex(seen))
+	})
+
+	t.Run("stops on context cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		clock := newFakeClock(time.Now())
+		done := make(chan error, 1)
+		go func() {
+			done <- watchDeal(ctx, clock, time.Minute, func(context.Context) (*Deal, error) {
+				return snapshot(5, DealStatusBought), nil
+			}, func([]BotEvent) {})
+		}()
+
+		clock.WaitForTimers(t, 1)
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	t.Run("returns poll errors", func(t *testing.T) {
+		boom := errors.New("boom")
+		err := watchDeal(context.Background(), newFakeClock(time.Now()), time.Minute, func(context.Context) (*Deal, error) {
+			return nil, boom
+		}, func([]BotEvent) {
+			t.Fatal("callback must not be invoked")
+		})
+		require.ErrorIs(t, err, boom)
+	})
+}
+
+func TestWatchDealInvalidInterval(t *testing.T) {
+	client, err := New3CommasClient(defaultTestOptions()...)
+	require.NoError(t, err)
+	require.Error(t, client.WatchDeal(context.Background(), 1, 0, func([]BotEvent) {}))
+}